@@ -27,6 +27,12 @@ var (
 )
 
 func deriveEnvVarName(s string) string {
+	return strings.ToUpper(strings.Join(splitWords(s), "_"))
+}
+
+// splitWords splits a name into its words at case changes ("MyField" to
+// "My" and "Field") and at all characters not being letters or numbers
+func splitWords(s string) []string {
 	var (
 		words []string
 		word  []rune
@@ -58,7 +64,9 @@ func deriveEnvVarName(s string) string {
 			word = []rune{}
 		}
 	}
-	words = append(words, string(word))
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
 
-	return strings.ToUpper(strings.Join(words, "_"))
+	return words
 }
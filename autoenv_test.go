@@ -3,7 +3,9 @@ package rconfig
 import (
 	"testing"
 
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDeriveEnvVarName(t *testing.T) {
@@ -27,3 +29,124 @@ func TestDeriveEnvVarName(t *testing.T) {
 		assert.Equal(t, expect, deriveEnvVarName(test))
 	}
 }
+
+func TestEnvPrefix(t *testing.T) {
+	type testcfg struct {
+		Port    int    `flag:"port" description:"Port to listen on"`
+		LogMode string `env:"LOG_MODE"`
+		Replica struct {
+			Host string
+		} `prefix:"replica"`
+	}
+
+	t.Setenv("PORT", "1")
+	t.Setenv("MYAPP_PORT", "2")
+	t.Setenv("LOG_MODE", "plain")
+	t.Setenv("MYAPP_LOG_MODE", "json")
+	t.Setenv("MYAPP_REPLICA_HOST", "replica")
+
+	l := New()
+	l.AutoEnv(true)
+	l.SetEnvPrefix("MYAPP")
+
+	var cfg testcfg
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, 2, cfg.Port)
+	assert.Equal(t, "plain", cfg.LogMode, "explicit env tags must not be prefixed by default")
+	assert.Equal(t, "replica", cfg.Replica.Host)
+	assert.Equal(t, "Port to listen on (ENV: MYAPP_PORT)", l.fs.Lookup("port").Usage)
+
+	l.PrefixExplicitEnv(true)
+	l.SetEnvPrefix("MYAPP_")
+
+	cfg = testcfg{}
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, 2, cfg.Port)
+	assert.Equal(t, "json", cfg.LogMode)
+}
+
+func TestEnvPrefixApplyEnvAndDefaults(t *testing.T) {
+	var cfg struct {
+		Port int `flag:"port" default:"80"`
+	}
+
+	t.Setenv("MYAPP_PORT", "8080")
+
+	l := New()
+	l.AutoEnv(true)
+	l.SetEnvPrefix("MYAPP")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Contains(t, fs.Lookup("port").Usage, "(ENV: MYAPP_PORT)")
+}
+
+func TestAutoEnvNested(t *testing.T) {
+	type Base struct {
+		Debug bool
+	}
+
+	type testcfg struct {
+		Base
+		Port     int
+		Database struct {
+			Host    string
+			MaxConn int `env:"DB_CONNS"`
+		}
+		Cache struct {
+			Host string
+		}
+		Replica struct {
+			Host string
+		} `prefix:"rep"`
+	}
+
+	t.Setenv("DEBUG", "true")
+	t.Setenv("PORT", "8080")
+	t.Setenv("DATABASE_HOST", "db")
+	t.Setenv("DB_CONNS", "5")
+	t.Setenv("CACHE_HOST", "cache")
+	t.Setenv("REP_HOST", "replica")
+
+	l := New()
+	l.AutoEnv(true)
+
+	var cfg testcfg
+	assert.ErrorContains(t, l.parse(&cfg, []string{}), "fields Database.Host and Cache.Host both use env variable HOST")
+
+	l.AutoEnvNested(true)
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, "db", cfg.Database.Host)
+	assert.Equal(t, 5, cfg.Database.MaxConn)
+	assert.Equal(t, "cache", cfg.Cache.Host)
+	assert.Equal(t, "replica", cfg.Replica.Host)
+
+	t.Setenv("MYAPP_DATABASE_HOST", "prefixed")
+	l.SetEnvPrefix("MYAPP")
+
+	cfg = testcfg{}
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, "prefixed", cfg.Database.Host)
+}
+
+func TestAutoEnvNestedCollision(t *testing.T) {
+	l := New()
+	l.AutoEnv(true)
+	l.AutoEnvNested(true)
+
+	assert.ErrorContains(t, l.parse(&struct {
+		DatabaseHost string
+		Database     struct {
+			Host string
+		}
+	}{}, []string{}), "both use env variable DATABASE_HOST")
+}
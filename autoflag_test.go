@@ -0,0 +1,130 @@
+package rconfig
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoFlag(t *testing.T) {
+	type Base struct {
+		Debug bool
+	}
+
+	type testcfg struct {
+		Base
+		ListenAddr string `default:":8080"`
+		Verbose    bool   `flag:"verbose,v"`
+		Secret     string `flag:"-" default:"hidden"`
+		Database   struct {
+			Host    string
+			MaxConn int `description:"Maximum connections"`
+		}
+		Replica struct {
+			Host string
+		} `prefix:"replica"`
+	}
+
+	l := New()
+	l.AutoFlag(true)
+
+	var cfg testcfg
+	require.NoError(t, l.parse(&cfg, []string{
+		"--debug", "--listen-addr", ":9090", "-v",
+		"--database-host", "db", "--database-max-conn=5",
+		"--replica-host", "replica",
+	}))
+
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, ":9090", cfg.ListenAddr)
+	assert.True(t, cfg.Verbose)
+	assert.Equal(t, "hidden", cfg.Secret)
+	assert.Equal(t, "db", cfg.Database.Host)
+	assert.Equal(t, 5, cfg.Database.MaxConn)
+	assert.Equal(t, "replica", cfg.Replica.Host)
+
+	assert.Nil(t, l.fs.Lookup("secret"))
+	assert.Equal(t, "Maximum connections", l.fs.Lookup("database-max-conn").Usage)
+	assert.Equal(t, ":8080", l.fs.Lookup("listen-addr").DefValue)
+}
+
+func TestAutoFlagOptOut(t *testing.T) {
+	var cfg struct {
+		Name string `flag:"-"`
+	}
+	cfg.Name = "keep"
+
+	require.NoError(t, New().parse(&cfg, []string{}))
+	assert.Equal(t, "keep", cfg.Name, "opted out fields must be treated as untagged")
+}
+
+func TestAutoFlagNaming(t *testing.T) {
+	var cfg struct {
+		Database struct {
+			Host string
+		}
+	}
+
+	l := New()
+	l.AutoFlag(true)
+	l.SetFlagNaming(DotCase)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{"--database.host", "db"}))
+	assert.Equal(t, "db", cfg.Database.Host)
+}
+
+func TestAutoFlagCollision(t *testing.T) {
+	l := New()
+	l.AutoFlag(true)
+
+	assert.ErrorContains(t, l.parse(&struct {
+		DatabaseHost string
+		Database     struct {
+			Host string
+		}
+	}{}, []string{}), "both use flag --database-host")
+}
+
+func TestAutoFlagUnsupportedTypes(t *testing.T) {
+	type Inner struct {
+		Value string
+	}
+
+	var cfg struct {
+		Callback func()
+		Events   chan string
+		Any      interface{}
+		In       *Inner
+		Ins      []Inner
+		Name     string
+	}
+
+	l := New()
+	l.AutoFlag(true)
+	l.AutoEnv(true)
+	require.NoError(t, l.parse(&cfg, []string{"--name", "test"}))
+
+	assert.Equal(t, "test", cfg.Name)
+	for _, name := range []string{"callback", "events", "any", "in", "ins"} {
+		assert.Nil(t, l.fs.Lookup(name), name)
+	}
+	assert.Nil(t, cfg.In)
+}
+
+func TestUnsupportedTypeErrors(t *testing.T) {
+	type Inner struct {
+		Value string
+	}
+
+	assert.ErrorContains(t, New().parse(&struct {
+		In *Inner `default:"zz"`
+	}{}, []string{}), "unsupported type")
+
+	assert.ErrorContains(t, New().parse(&struct {
+		Events chan string `default:"zz"`
+	}{}, []string{}), "unsupported type")
+}
@@ -0,0 +1,143 @@
+package rconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoVarDefault(t *testing.T) {
+	type Base struct {
+		Debug bool
+	}
+
+	type testcfg struct {
+		Base
+		Name     string `default:"unknown"`
+		Explicit string `vardefault:"other"`
+		RabbitMQ struct {
+			Host    string
+			Port    int      `yaml:"port_number"`
+			Queues  []string `json:"queueNames"`
+			Labels  map[string]string
+			Ignored string `yaml:"-" default:"kept"`
+		} `yaml:"rabbitmq"`
+	}
+
+	defaults, err := VarDefaultsFromYAML([]byte(`---
+debug: true
+name: from-yaml
+other: explicit
+rabbitmq:
+  host: mq.example.com
+  port_number: 5672
+  queueNames: [jobs, events]
+  labels:
+    team: core
+  ignored: ignored
+`))
+	require.NoError(t, err)
+
+	l := New()
+	l.AutoVarDefault(true)
+	l.SetVariableDefaults(defaults)
+
+	var cfg testcfg
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, "from-yaml", cfg.Name)
+	assert.Equal(t, "explicit", cfg.Explicit)
+	assert.Equal(t, "mq.example.com", cfg.RabbitMQ.Host)
+	assert.Equal(t, 5672, cfg.RabbitMQ.Port)
+	assert.Equal(t, []string{"jobs", "events"}, cfg.RabbitMQ.Queues)
+	assert.Equal(t, map[string]string{"team": "core"}, cfg.RabbitMQ.Labels)
+	assert.Equal(t, "kept", cfg.RabbitMQ.Ignored)
+}
+
+func TestAutoVarDefaultRoot(t *testing.T) {
+	type testcfg struct {
+		RabbitMQ struct {
+			Host string `default:"localhost"`
+		}
+	}
+
+	l := New()
+	l.AutoVarDefault(true)
+	l.SetVarDefaultRoot("config")
+	l.SetVariableDefaults(map[string]string{
+		"rabbitmq.host":        "without-root",
+		"config.rabbitmq.host": "with-root",
+	})
+
+	var cfg testcfg
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, "with-root", cfg.RabbitMQ.Host)
+
+	l.SetVarDefaultRoot("")
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, "without-root", cfg.RabbitMQ.Host)
+
+	l.AutoVarDefault(false)
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, "localhost", cfg.RabbitMQ.Host)
+}
+
+func TestAutoVarDefaultPrecedence(t *testing.T) {
+	var cfg struct {
+		Port int `default:"80" env:"RCONFIG_TEST_AUTOVARDEFAULT_PORT" flag:"port"`
+	}
+
+	l := New()
+	l.AutoVarDefault(true)
+	l.SetVariableDefaults(map[string]string{"port": "8080"})
+
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, 8080, cfg.Port)
+
+	t.Setenv("RCONFIG_TEST_AUTOVARDEFAULT_PORT", "9090")
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, 9090, cfg.Port)
+
+	require.NoError(t, l.parse(&cfg, []string{"--port", "1"}))
+	assert.Equal(t, 1, cfg.Port)
+}
+
+func TestAutoVarDefaultNaming(t *testing.T) {
+	var cfg struct {
+		RabbitMQ struct {
+			MaxConns int
+		}
+	}
+
+	l := New()
+	l.AutoVarDefault(true)
+	l.SetVarDefaultNaming(DotCase)
+	l.SetVariableDefaults(map[string]string{"rabbit.mq.max.conns": "5"})
+
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, 5, cfg.RabbitMQ.MaxConns)
+}
+
+func TestFoldVarDefaultKey(t *testing.T) {
+	l := New()
+	l.SetVariableDefaults(map[string]string{
+		"Database.Host":   "a",
+		"database.port":   "b",
+		"Labels.team":     "c",
+		"hosts.0":         "d",
+		"databaseX.other": "e",
+	})
+
+	for key, expect := range map[string]string{
+		"database.host": "Database.Host",
+		"database.port": "database.port",
+		"labels":        "Labels",
+		"Hosts":         "hosts",
+		"database":      "database",
+		"missing":       "missing",
+	} {
+		assert.Equal(t, expect, l.foldVarDefaultKey(key), key)
+	}
+}
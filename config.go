@@ -16,12 +16,35 @@ import (
 	validator "github.com/go-playground/validator/v10"
 )
 
-type afterFunc func() error
+// Loader holds the state of one configuration parser: its FlagSet, the
+// variable defaults, the AutoEnv setting and the time parser formats.
+// Multiple Loaders are fully independent of each other, so several
+// configurations can be parsed side by side or concurrently as long as
+// every goroutine uses its own Loader.
+type Loader struct {
+	autoEnv            bool
+	autoEnvNested      bool
+	autoFlag           bool
+	autoVarDefault     bool
+	configFlag         *configFlag
+	configFlagDefaults map[string]string
+	configFile         map[string]interface{}
+	decoders           map[reflect.Type]DecoderFunc
+	envNaming          NamingStrategy
+	envOverlay         map[string]string
+	envPrefix          string
+	envPrefixExplicit  bool
+	flagNaming         NamingStrategy
+	fs                 *pflag.FlagSet
+	timeParserFormats  []string
+	varDefaultNaming   NamingStrategy
+	varDefaultRoot     string
+	variableDefaults   map[string]string
+}
 
 var (
-	autoEnv          bool
-	fs               *pflag.FlagSet
-	variableDefaults map[string]string
+	// defaultLoader is used by the package level functions
+	defaultLoader = New()
 
 	timeParserFormats = []string{
 		// Default constants
@@ -34,11 +57,27 @@ var (
 		"2006-01-02 15:04:05", "2006-01-02 15:04:05Z07:00", // Simplified ISO time format
 		"01/02/2006 15:04:05", "01/02/2006 15:04:05Z07:00", // US time format
 		"02.01.2006 15:04:05", "02.01.2006 15:04:05Z07:00", // DE time format
+		"2006-01-02", "15:04:05", // Plain date or time
 	}
 )
 
-func init() {
-	variableDefaults = make(map[string]string)
+// New creates a new Loader with an empty set of variable defaults, the
+// built-in time parser formats and the decoders registered using the
+// package level RegisterDecoder
+func New() *Loader {
+	decoders := make(map[reflect.Type]DecoderFunc, len(registeredDecoders))
+	for t, dec := range registeredDecoders {
+		decoders[t] = dec
+	}
+
+	return &Loader{
+		decoders:          decoders,
+		envNaming:         ScreamingSnakeCase,
+		flagNaming:        KebabCase,
+		timeParserFormats: append([]string{}, timeParserFormats...),
+		varDefaultNaming:  dotPath,
+		variableDefaults:  make(map[string]string),
+	}
 }
 
 // RegisterFlags registers all flags from the config struct to the provided FlagSet.
@@ -47,6 +86,13 @@ func init() {
 // parses the flags, call ApplyEnvAndDefaults to apply environment variables and
 // vardefaults to flags that weren't explicitly set.
 func RegisterFlags(config interface{}, flagSet *pflag.FlagSet) error {
+	return defaultLoader.RegisterFlags(config, flagSet)
+}
+
+// RegisterFlags registers all flags from the config struct to the provided
+// FlagSet using the settings of this Loader. See the package level
+// RegisterFlags for details.
+func (l *Loader) RegisterFlags(config interface{}, flagSet *pflag.FlagSet) error {
 	if reflect.TypeOf(config).Kind() != reflect.Ptr {
 		return errors.New("RegisterFlags: config must be a pointer")
 	}
@@ -55,15 +101,37 @@ func RegisterFlags(config interface{}, flagSet *pflag.FlagSet) error {
 		return errors.New("RegisterFlags: config must be a pointer to struct")
 	}
 
-	_, err := execTags(config, flagSet)
-	return err
+	// The file selected by the config file flag is only known after parsing
+	l.configFlagDefaults = nil
+
+	fields, err := l.collectFields(config)
+	if err != nil {
+		return err
+	}
+
+	if err = l.applyValues(fields); err != nil {
+		return err
+	}
+
+	if err = l.registerFlags(fields, flagSet); err != nil {
+		return err
+	}
+
+	return l.registerConfigFlag(flagSet)
 }
 
 // ApplyEnvAndDefaults applies environment variables and vardefaults to a config struct
 // whose flags have already been registered and parsed by an external FlagSet (e.g., Cobra).
-// This maintains the precedence: flag (if changed) > env > vardefault > default.
+// This maintains the precedence: flag (if changed) > env > file > vardefault > default.
 // Only fields where the flag was NOT explicitly set by the user will be updated.
 func ApplyEnvAndDefaults(config interface{}, flagSet *pflag.FlagSet) error {
+	return defaultLoader.ApplyEnvAndDefaults(config, flagSet)
+}
+
+// ApplyEnvAndDefaults applies environment variables and vardefaults of this
+// Loader to a config struct whose flags have already been parsed by an
+// external FlagSet. See the package level ApplyEnvAndDefaults for details.
+func (l *Loader) ApplyEnvAndDefaults(config interface{}, flagSet *pflag.FlagSet) error {
 	if reflect.TypeOf(config).Kind() != reflect.Ptr {
 		return errors.New("ApplyEnvAndDefaults: config must be a pointer")
 	}
@@ -72,56 +140,55 @@ func ApplyEnvAndDefaults(config interface{}, flagSet *pflag.FlagSet) error {
 		return errors.New("ApplyEnvAndDefaults: config must be a pointer to struct")
 	}
 
-	return applyEnvAndDefaults(reflect.ValueOf(config).Elem(), reflect.TypeOf(config).Elem(), flagSet)
-}
-
-func applyEnvAndDefaults(val reflect.Value, typ reflect.Type, flagSet *pflag.FlagSet) error {
-	for i := 0; i < val.NumField(); i++ {
-		valField := val.Field(i)
-		typeField := typ.Field(i)
-
-		// Handle nested structs recursively
-		if typeField.Type.Kind() == reflect.Struct && typeField.Type != reflect.TypeOf(time.Time{}) {
-			if err := applyEnvAndDefaults(valField, typeField.Type, flagSet); err != nil {
-				return err
-			}
-			continue
-		}
-
-		// Get value from vardefault/env with fallback to default tag
-		value := varDefault(typeField.Tag.Get("vardefault"), typeField.Tag.Get("default"))
-		value = envDefault(typeField, value)
-
-		// Check if this field has a flag
-		flagName := typeField.Tag.Get("flag")
-		if flagName != "" {
-			parts := strings.Split(flagName, ",")
-			flag := flagSet.Lookup(parts[0])
+	if err := l.loadConfigFlagFile(config, flagSet, nil); err != nil {
+		return err
+	}
 
-			// If flag was explicitly set by user, skip this field (maintain precedence)
-			if flag != nil && flag.Changed {
-				continue
-			}
+	fields, err := l.collectFields(config)
+	if err != nil {
+		return err
+	}
 
-			// Flag exists but wasn't set by user - update it with env/vardefault
-			if flag != nil {
-				if err := flag.Value.Set(value); err != nil {
-					return fmt.Errorf("setting flag %s: %w", parts[0], err)
-				}
+	for _, f := range fields {
+		if f.flag != "" {
+			if flag := flagSet.Lookup(f.flag); flag != nil && flag.Changed {
+				// Flag was explicitly set by the user, keep its value to maintain precedence
 				continue
 			}
 		}
 
-		// No flag or flag not registered - set field directly (for env/vardefault-only fields)
-		if err := setFieldValue(valField, typeField.Type, value); err != nil {
-			return fmt.Errorf("setting field %s: %w", typeField.Name, err)
+		if err = l.applyValue(f); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func setFieldValue(field reflect.Value, fieldType reflect.Type, value string) error {
+// setFieldValue parses the string representation of a value into the
+// given field. It is the single place values from flags, ENV, variable
+// defaults and default tags are converted, so all sources share the same
+// parsing rules. An empty value resets basic types to their zero value.
+// Integers are parsed as decimal numbers, only flag values additionally
+// accept base prefixes (see fieldValue.setInteger).
+//
+//nolint:gocyclo // Flat switch over all supported types
+func (l *Loader) setFieldValue(field reflect.Value, fieldType reflect.Type, value string) error {
+	if l.isCustomType(fieldType) {
+		return l.setCustomValue(field, value)
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Map, reflect.Slice, reflect.Ptr:
+		// Empty values are meaningful for these types
+
+	default:
+		if value == "" {
+			field.Set(reflect.Zero(fieldType))
+			return nil
+		}
+	}
+
 	// Handle special types first
 	switch fieldType {
 	case reflect.TypeOf(time.Duration(0)):
@@ -139,7 +206,7 @@ func setFieldValue(field reflect.Value, fieldType reflect.Type, value string) er
 			return nil
 		}
 		// Try time format parsing
-		for _, format := range timeParserFormats {
+		for _, format := range l.timeParserFormats {
 			if t, err := time.Parse(format, value); err == nil {
 				field.Set(reflect.ValueOf(t))
 				return nil
@@ -154,7 +221,11 @@ func setFieldValue(field reflect.Value, fieldType reflect.Type, value string) er
 		field.SetString(value)
 
 	case reflect.Bool:
-		field.SetBool(value == "true")
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		v, err := parseIntForType(value, 10, fieldType.Kind())
@@ -171,20 +242,51 @@ func setFieldValue(field reflect.Value, fieldType reflect.Type, value string) er
 		field.SetUint(v)
 
 	case reflect.Float32, reflect.Float64:
-		v, err := strconv.ParseFloat(value, 64)
+		v, err := strconv.ParseFloat(value, fieldType.Bits())
 		if err != nil {
 			return err
 		}
 		field.SetFloat(v)
+
+	case reflect.Map:
+		m, err := l.parseMap(value, fieldType)
+		if err != nil {
+			return err
+		}
+		field.Set(m)
+
+	case reflect.Slice:
+		if isByteSlice(fieldType) {
+			field.Set(reflect.ValueOf([]byte(value)).Convert(fieldType))
+			return nil
+		}
+
+		v, err := l.parseSlice(value, fieldType, ",")
+		if err != nil {
+			return err
+		}
+		field.Set(v)
+
+	case reflect.Ptr:
+		v := reflect.New(fieldType.Elem())
+		if err := l.setFieldValue(v.Elem(), fieldType.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(v)
+
+	default:
+		return fmt.Errorf("unsupported type %s", fieldType)
 	}
 
 	return nil
 }
 
 // Parse takes the pointer to a struct filled with variables which should be read
-// from ENV, default or flag. The precedence in this is flag > ENV > default. So
-// if a flag is specified on the CLI it will overwrite the ENV and otherwise ENV
-// overwrites the default specified.
+// from flag, ENV, configuration file, variable defaults or default. The
+// precedence in this is flag > ENV > file > vardefault > default. So if a flag
+// is specified on the CLI it will overwrite the ENV, ENV overwrites the value
+// from the configuration file (see ParseWithFile) and so on down to the
+// default specified.
 //
 // For your configuration struct you can use the following struct-tags to control
 // the behavior of rconfig:
@@ -192,13 +294,24 @@ func setFieldValue(field reflect.Value, fieldType reflect.Type, value string) er
 //	default: Set a default value
 //	vardefault: Read the default value from the variable defaults
 //	env: Read the value from this environment variable
-//	flag: Flag to read in format "long,short" (for example "listen,l")
+//	flag: Flag to read in format "long,short" (for example "listen,l"), "-" to
+//	      exclude the field from AutoFlag
 //	description: A help text for Usage output to guide your users
+//	prefix: On a sub-struct field, prefix the flags ("replica-"), env variables
+//	        ("REPLICA_") and vardefault keys ("replica.") of all fields within.
+//	        Flag shorthands of those fields are dropped as they cannot be
+//	        prefixed and would collide when the sub-struct is used twice.
 //
 // The format you need to specify those values you can see in the example to this
 // function.
 func Parse(config interface{}) error {
-	return parse(config, nil)
+	return defaultLoader.Parse(config)
+}
+
+// Parse reads the configuration into the given struct pointer using the
+// settings of this Loader. See the package level Parse for details.
+func (l *Loader) Parse(config interface{}) error {
+	return l.parse(config, nil)
 }
 
 // ParseAndValidate works exactly like Parse but implements an additional run of
@@ -207,418 +320,218 @@ func Parse(config interface{}) error {
 //
 // https://github.com/go-validator/validator/tree/v2#usage
 func ParseAndValidate(config interface{}) error {
-	return parseAndValidate(config, nil)
+	return defaultLoader.ParseAndValidate(config)
+}
+
+// ParseAndValidate works exactly like Parse but additionally validates the
+// configuration struct. See the package level ParseAndValidate for details.
+func (l *Loader) ParseAndValidate(config interface{}) error {
+	return l.parseAndValidate(config, nil)
 }
 
 // Args returns the non-flag command-line arguments.
 func Args() []string {
-	return fs.Args()
+	return defaultLoader.Args()
+}
+
+// Args returns the non-flag command-line arguments of the last Parse call
+// on this Loader.
+func (l *Loader) Args() []string {
+	if l.fs == nil {
+		return nil
+	}
+	return l.fs.Args()
 }
 
 // AddTimeParserFormats adds custom formats to parse time.Time fields
 func AddTimeParserFormats(f ...string) {
-	timeParserFormats = append(timeParserFormats, f...)
+	defaultLoader.AddTimeParserFormats(f...)
+}
+
+// AddTimeParserFormats adds custom formats to parse time.Time fields
+func (l *Loader) AddTimeParserFormats(f ...string) {
+	l.timeParserFormats = append(l.timeParserFormats, f...)
 }
 
 // AutoEnv enables or disables automated env variable guessing. If no `env` struct
 // tag was set and AutoEnv is enabled the env variable name is derived from the
 // name of the field: `MyFieldName` will get `MY_FIELD_NAME`
 func AutoEnv(enable bool) {
-	autoEnv = enable
+	defaultLoader.AutoEnv(enable)
 }
 
-// Usage prints a basic usage with the corresponding defaults for the flags to
-// os.Stdout. The defaults are derived from the `default` struct-tag and the ENV.
-func Usage() {
-	if fs != nil && fs.Parsed() {
-		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-		fs.PrintDefaults()
-	}
+// AutoEnv enables or disables automated env variable guessing for this Loader
+func (l *Loader) AutoEnv(enable bool) {
+	l.autoEnv = enable
 }
 
-// SetVariableDefaults presets the parser with a map of default values to be used
-// when specifying the vardefault tag
-func SetVariableDefaults(defaults map[string]string) {
-	variableDefaults = defaults
+// AutoFlag enables or disables automated flag names. If no `flag` struct tag
+// was set and AutoFlag is enabled the flag name is derived from the path of
+// the field: `ListenAddr` will get `--listen-addr`, `Database.Host` will get
+// `--database-host`. Use `flag:"-"` to exclude a field.
+func AutoFlag(enable bool) {
+	defaultLoader.AutoFlag(enable)
 }
 
-//revive:disable-next-line:confusing-naming // The public function is only a wrapper with less args
-func parseAndValidate(in interface{}, args []string) (err error) {
-	if err = parse(in, args); err != nil {
-		return err
-	}
-
-	if err = validator.New().Struct(in); err != nil {
-		return fmt.Errorf("validating values: %w", err)
-	}
+// AutoFlag enables or disables automated flag names for this Loader
+func (l *Loader) AutoFlag(enable bool) {
+	l.autoFlag = enable
+}
 
-	return nil
+// AutoVarDefault enables or disables automated vardefault keys. If no
+// `vardefault` struct tag was set and AutoVarDefault is enabled the key is
+// derived from the path of the field using the `yaml` or `json` tags or the
+// field names: `Database.Host` will read `database.host` from variable
+// defaults created from a file mirroring the struct layout. Derived keys are
+// matched case-insensitively. See SetVarDefaultRoot to read the keys below
+// a common root.
+func AutoVarDefault(enable bool) {
+	defaultLoader.AutoVarDefault(enable)
 }
 
-//revive:disable-next-line:confusing-naming // The public function is only a wrapper with less args
-func parse(in interface{}, args []string) error {
-	if args == nil {
-		args = os.Args
-	}
+// AutoVarDefault enables or disables automated vardefault keys for this
+// Loader
+func (l *Loader) AutoVarDefault(enable bool) {
+	l.autoVarDefault = enable
+}
 
-	fs = pflag.NewFlagSet(os.Args[0], pflag.ExitOnError)
-	afterFuncs, err := execTags(in, fs)
-	if err != nil {
-		return err
-	}
+// SetVarDefaultRoot sets the key the vardefault keys derived by
+// AutoVarDefault are located below: with the root "config" the field
+// `RabbitMQ.Host` reads `config.rabbitmq.host`
+func SetVarDefaultRoot(root string) {
+	defaultLoader.SetVarDefaultRoot(root)
+}
 
-	if err := fs.Parse(args); err != nil {
-		return fmt.Errorf("parsing flag-set: %w", err)
+// SetVarDefaultRoot sets the root of the vardefault keys derived by
+// AutoVarDefault for this Loader
+func (l *Loader) SetVarDefaultRoot(root string) {
+	if root != "" && !strings.HasSuffix(root, ".") {
+		root += "."
 	}
+	l.varDefaultRoot = root
+}
 
-	for _, f := range afterFuncs {
-		if err := f(); err != nil {
-			return err
-		}
-	}
+// AutoEnvNested enables or disables building the env variable names guessed
+// by AutoEnv from the full path of the field through nested structs:
+// `Database.Host` will get `DATABASE_HOST` instead of `HOST`. Sub-structs
+// carrying a `prefix` tag contribute their prefix instead of their name.
+func AutoEnvNested(enable bool) {
+	defaultLoader.AutoEnvNested(enable)
+}
 
-	return nil
+// AutoEnvNested enables or disables building the env variable names guessed
+// by AutoEnv from the full field path for this Loader
+func (l *Loader) AutoEnvNested(enable bool) {
+	l.autoEnvNested = enable
 }
 
-//nolint:funlen,gocognit,gocyclo // Hard to split
-func execTags(in interface{}, fs *pflag.FlagSet) ([]afterFunc, error) {
-	if reflect.TypeOf(in).Kind() != reflect.Ptr {
-		return nil, errors.New("calling parser with non-pointer")
-	}
+// SetEnvPrefix sets a prefix for env variable names derived by AutoEnv:
+// with the prefix "MYAPP" the field `Port` reads `MYAPP_PORT`. Names given
+// in `env` tags are only prefixed when enabled using PrefixExplicitEnv.
+func SetEnvPrefix(prefix string) {
+	defaultLoader.SetEnvPrefix(prefix)
+}
 
-	if reflect.ValueOf(in).Elem().Kind() != reflect.Struct {
-		return nil, errors.New("calling parser with pointer to non-struct")
+// SetEnvPrefix sets a prefix for env variable names derived by AutoEnv for
+// this Loader. See the package level SetEnvPrefix for details.
+func (l *Loader) SetEnvPrefix(prefix string) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
 	}
+	l.envPrefix = prefix
+}
 
-	afterFuncs := []afterFunc{}
-
-	st := reflect.ValueOf(in).Elem()
-	for i := 0; i < st.NumField(); i++ {
-		valField := st.Field(i)
-		typeField := st.Type().Field(i)
-
-		if typeField.Tag.Get("default") == "" && typeField.Tag.Get("env") == "" && typeField.Tag.Get("flag") == "" && typeField.Type.Kind() != reflect.Struct {
-			// None of our supported tags is present and it's not a sub-struct
-			continue
-		}
+// PrefixExplicitEnv enables or disables applying the prefix set using
+// SetEnvPrefix to the names given in `env` tags too
+func PrefixExplicitEnv(enable bool) {
+	defaultLoader.PrefixExplicitEnv(enable)
+}
 
-		value := varDefault(typeField.Tag.Get("vardefault"), typeField.Tag.Get("default"))
-		value = envDefault(typeField, value)
-		parts := strings.Split(typeField.Tag.Get("flag"), ",")
-
-		switch typeField.Type {
-		case reflect.TypeOf(time.Duration(0)):
-			v, err := time.ParseDuration(value)
-			if err != nil {
-				if value != "" {
-					return nil, fmt.Errorf("parsing time.Duration: %w", err)
-				}
-				v = time.Duration(0)
-			}
+// PrefixExplicitEnv enables or disables applying the env prefix to the
+// names given in `env` tags for this Loader
+func (l *Loader) PrefixExplicitEnv(enable bool) {
+	l.envPrefixExplicit = enable
+}
 
-			if typeField.Tag.Get("flag") != "" {
-				desc := buildDescription(typeField)
-				if len(parts) == 1 {
-					fs.DurationVar(valField.Addr().Interface().(*time.Duration), parts[0], v, desc)
-				} else {
-					fs.DurationVarP(valField.Addr().Interface().(*time.Duration), parts[0], parts[1], v, desc)
-				}
-			} else {
-				valField.Set(reflect.ValueOf(v))
-			}
-			continue
-
-		case reflect.TypeOf(time.Time{}):
-			var sVar string
-
-			if typeField.Tag.Get("flag") != "" {
-				desc := buildDescription(typeField)
-				if len(parts) == 1 {
-					fs.StringVar(&sVar, parts[0], value, desc)
-				} else {
-					fs.StringVarP(&sVar, parts[0], parts[1], value, desc)
-				}
-			} else {
-				sVar = value
-			}
+// Usage prints a basic usage with the corresponding defaults for the flags to
+// os.Stdout. The defaults are derived from the `default` struct-tag and the ENV.
+func Usage() {
+	defaultLoader.Usage()
+}
 
-			afterFuncs = append(afterFuncs, func(valField reflect.Value, sVar *string) func() error {
-				return func() error {
-					if *sVar == "" {
-						// No time, no problem
-						return nil
-					}
-
-					// Check whether we could have a timestamp
-					if ts, err := strconv.ParseInt(*sVar, 10, 64); err == nil {
-						t := time.Unix(ts, 0)
-						valField.Set(reflect.ValueOf(t))
-						return nil
-					}
-
-					// We haven't so lets walk through possible time formats
-					matched := false
-					for _, tf := range timeParserFormats {
-						if t, err := time.Parse(tf, *sVar); err == nil {
-							valField.Set(reflect.ValueOf(t))
-							return nil
-						}
-					}
-
-					if !matched {
-						return fmt.Errorf("value %q did not match expected time formats", *sVar)
-					}
-
-					return nil
-				}
-			}(valField, &sVar))
-
-			continue
-		}
+// Usage prints a basic usage for the flags of the last Parse call on this
+// Loader to os.Stderr.
+func (l *Loader) Usage() {
+	if l.fs != nil && l.fs.Parsed() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+		l.fs.PrintDefaults()
+	}
+}
 
-		switch typeField.Type.Kind() {
-		case reflect.String:
-			if typeField.Tag.Get("flag") != "" {
-				desc := buildDescription(typeField)
-				if len(parts) == 1 {
-					fs.StringVar(valField.Addr().Interface().(*string), parts[0], value, desc)
-				} else {
-					fs.StringVarP(valField.Addr().Interface().(*string), parts[0], parts[1], value, desc)
-				}
-			} else {
-				valField.SetString(value)
-			}
+// SetVariableDefaults presets the parser with a map of default values to be used
+// when specifying the vardefault tag
+func SetVariableDefaults(defaults map[string]string) {
+	defaultLoader.SetVariableDefaults(defaults)
+}
 
-		case reflect.Bool:
-			v := value == "true"
-			if typeField.Tag.Get("flag") != "" {
-				desc := buildDescription(typeField)
-				if len(parts) == 1 {
-					fs.BoolVar(valField.Addr().Interface().(*bool), parts[0], v, desc)
-				} else {
-					fs.BoolVarP(valField.Addr().Interface().(*bool), parts[0], parts[1], v, desc)
-				}
-			} else {
-				valField.SetBool(v)
-			}
+// SetVariableDefaults presets this Loader with a map of default values to be
+// used when specifying the vardefault tag
+func (l *Loader) SetVariableDefaults(defaults map[string]string) {
+	l.variableDefaults = defaults
+}
 
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			vt, err := parseIntForType(value, 10, typeField.Type.Kind()) //nolint:mnd
-			if err != nil {
-				if value != "" {
-					return nil, fmt.Errorf("parsing int: %w", err)
-				}
-				vt = 0
-			}
-			if typeField.Tag.Get("flag") != "" {
-				registerFlagInt(typeField.Type.Kind(), fs, valField.Addr().Interface(), parts, vt, buildDescription(typeField))
-			} else {
-				valField.SetInt(vt)
-			}
+//revive:disable-next-line:confusing-naming // The public function is only a wrapper with less args
+func parseAndValidate(in interface{}, args []string) error {
+	return defaultLoader.parseAndValidate(in, args)
+}
 
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			vt, err := parseUintForType(value, 10, typeField.Type.Kind()) //nolint:mnd
-			if err != nil {
-				if value != "" {
-					return nil, fmt.Errorf("parsing uint: %w", err)
-				}
-				vt = 0
-			}
-			if typeField.Tag.Get("flag") != "" {
-				registerFlagUint(typeField.Type.Kind(), fs, valField.Addr().Interface(), parts, vt, buildDescription(typeField))
-			} else {
-				valField.SetUint(vt)
-			}
+//revive:disable-next-line:confusing-naming // The public function is only a wrapper with less args
+func parse(in interface{}, args []string) error {
+	return defaultLoader.parse(in, args)
+}
 
-		case reflect.Float32, reflect.Float64:
-			vt, err := strconv.ParseFloat(value, 64)
-			if err != nil {
-				if value != "" {
-					return nil, fmt.Errorf("parsing float: %w", err)
-				}
-				vt = 0.0
-			}
-			if typeField.Tag.Get("flag") != "" {
-				registerFlagFloat(typeField.Type.Kind(), fs, valField.Addr().Interface(), parts, vt, buildDescription(typeField))
-			} else {
-				valField.SetFloat(vt)
-			}
+func (l *Loader) parseAndValidate(in interface{}, args []string) (err error) {
+	if err = l.parse(in, args); err != nil {
+		return err
+	}
 
-		case reflect.Struct:
-			afs, err := execTags(valField.Addr().Interface(), fs)
-			if err != nil {
-				return nil, err
-			}
-			afterFuncs = append(afterFuncs, afs...)
-
-		case reflect.Slice:
-			switch typeField.Type.Elem().Kind() {
-			case reflect.Int:
-				def := []int{}
-				for _, v := range strings.Split(value, ",") {
-					it, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
-					if err != nil {
-						return nil, fmt.Errorf("parsing int: %w", err)
-					}
-					def = append(def, int(it))
-				}
-				desc := buildDescription(typeField)
-				if len(parts) == 1 {
-					fs.IntSliceVar(valField.Addr().Interface().(*[]int), parts[0], def, desc)
-				} else {
-					fs.IntSliceVarP(valField.Addr().Interface().(*[]int), parts[0], parts[1], def, desc)
-				}
-			case reflect.String:
-				del := typeField.Tag.Get("delimiter")
-				if len(del) == 0 {
-					del = ","
-				}
-				def := []string{}
-				if value != "" {
-					def = strings.Split(value, del)
-				}
-				desc := buildDescription(typeField)
-				if len(parts) == 1 {
-					fs.StringSliceVar(valField.Addr().Interface().(*[]string), parts[0], def, desc)
-				} else {
-					fs.StringSliceVarP(valField.Addr().Interface().(*[]string), parts[0], parts[1], def, desc)
-				}
-			}
-		}
+	if err = validator.New().Struct(in); err != nil {
+		return fmt.Errorf("validating values: %w", err)
 	}
 
-	return afterFuncs, nil
+	return nil
 }
 
-func registerFlagFloat(t reflect.Kind, fs *pflag.FlagSet, field interface{}, parts []string, vt float64, desc string) {
-	switch t {
-	case reflect.Float32:
-		if len(parts) == 1 {
-			fs.Float32Var(field.(*float32), parts[0], float32(vt), desc)
-		} else {
-			fs.Float32VarP(field.(*float32), parts[0], parts[1], float32(vt), desc)
-		}
-	case reflect.Float64:
-		if len(parts) == 1 {
-			fs.Float64Var(field.(*float64), parts[0], vt, desc)
-		} else {
-			fs.Float64VarP(field.(*float64), parts[0], parts[1], vt, desc)
-		}
+func (l *Loader) parse(in interface{}, args []string) error {
+	if args == nil {
+		args = os.Args
 	}
-}
 
-func registerFlagInt(t reflect.Kind, fs *pflag.FlagSet, field interface{}, parts []string, vt int64, desc string) {
-	switch t {
-	case reflect.Int:
-		if len(parts) == 1 {
-			fs.IntVar(field.(*int), parts[0], int(vt), desc)
-		} else {
-			fs.IntVarP(field.(*int), parts[0], parts[1], int(vt), desc)
-		}
-	case reflect.Int8:
-		if len(parts) == 1 {
-			fs.Int8Var(field.(*int8), parts[0], int8(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		} else {
-			fs.Int8VarP(field.(*int8), parts[0], parts[1], int8(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		}
-	case reflect.Int16:
-		if len(parts) == 1 {
-			fs.Int16Var(field.(*int16), parts[0], int16(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		} else {
-			fs.Int16VarP(field.(*int16), parts[0], parts[1], int16(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		}
-	case reflect.Int32:
-		if len(parts) == 1 {
-			fs.Int32Var(field.(*int32), parts[0], int32(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		} else {
-			fs.Int32VarP(field.(*int32), parts[0], parts[1], int32(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		}
-	case reflect.Int64:
-		if len(parts) == 1 {
-			fs.Int64Var(field.(*int64), parts[0], vt, desc)
-		} else {
-			fs.Int64VarP(field.(*int64), parts[0], parts[1], vt, desc)
-		}
+	fields, err := l.collectFields(in)
+	if err != nil {
+		return err
 	}
-}
 
-func registerFlagUint(t reflect.Kind, fs *pflag.FlagSet, field interface{}, parts []string, vt uint64, desc string) {
-	switch t {
-	case reflect.Uint:
-		if len(parts) == 1 {
-			fs.UintVar(field.(*uint), parts[0], uint(vt), desc)
-		} else {
-			fs.UintVarP(field.(*uint), parts[0], parts[1], uint(vt), desc)
-		}
-	case reflect.Uint8:
-		if len(parts) == 1 {
-			fs.Uint8Var(field.(*uint8), parts[0], uint8(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		} else {
-			fs.Uint8VarP(field.(*uint8), parts[0], parts[1], uint8(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		}
-	case reflect.Uint16:
-		if len(parts) == 1 {
-			fs.Uint16Var(field.(*uint16), parts[0], uint16(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		} else {
-			fs.Uint16VarP(field.(*uint16), parts[0], parts[1], uint16(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		}
-	case reflect.Uint32:
-		if len(parts) == 1 {
-			fs.Uint32Var(field.(*uint32), parts[0], uint32(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		} else {
-			fs.Uint32VarP(field.(*uint32), parts[0], parts[1], uint32(vt), desc) //#nosec:G115 - Variable is guaranteed to match by strconv
-		}
-	case reflect.Uint64:
-		if len(parts) == 1 {
-			fs.Uint64Var(field.(*uint64), parts[0], vt, desc)
-		} else {
-			fs.Uint64VarP(field.(*uint64), parts[0], parts[1], vt, desc)
-		}
+	if err = l.loadConfigFlagFile(in, nil, args); err != nil {
+		return err
 	}
-}
-
-func envDefault(field reflect.StructField, def string) string {
-	value := def
 
-	env := field.Tag.Get("env")
-	if env == "" && autoEnv {
-		env = deriveEnvVarName(field.Name)
+	if err = l.applyValues(fields); err != nil {
+		return err
 	}
 
-	if env != "" {
-		// Use LookupEnv to distinguish between unset and empty
-		if e, ok := os.LookupEnv(env); ok {
-			value = e
-		}
+	l.fs = pflag.NewFlagSet(os.Args[0], pflag.ExitOnError)
+	if err = l.registerFlags(fields, l.fs); err != nil {
+		return err
 	}
 
-	return value
-}
-
-func varDefault(name, def string) string {
-	value := def
-
-	if name != "" {
-		if v, ok := variableDefaults[name]; ok {
-			value = v
-		}
+	if err = l.registerConfigFlag(l.fs); err != nil {
+		return err
 	}
 
-	return value
-}
-
-func buildDescription(field reflect.StructField) string {
-	desc := field.Tag.Get("description")
-	env := field.Tag.Get("env")
-	if env == "" && autoEnv {
-		env = deriveEnvVarName(field.Name)
-	}
-	if env != "" {
-		if desc != "" {
-			desc += fmt.Sprintf(" (ENV: %s)", env)
-		} else {
-			desc = fmt.Sprintf("(ENV: %s)", env)
-		}
+	if err = l.fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flag-set: %w", err)
 	}
-	return desc
+
+	return nil
 }
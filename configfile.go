@@ -0,0 +1,177 @@
+package rconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ParseWithFile works like Parse but additionally reads the given YAML (or
+// JSON) file as configuration layer. Values from the file are assigned to
+// the fields by their path within the struct using the `yaml` or `json` tag
+// of each field or the field name. The precedence is flag > ENV > file >
+// vardefault > default.
+//
+// The file is only used for this call, a configuration file set before
+// using SetConfigFile is restored afterwards.
+func ParseWithFile(config interface{}, filename string) error {
+	return defaultLoader.ParseWithFile(config, filename)
+}
+
+// ParseWithFile works like Parse but additionally reads the given file as
+// configuration layer. See the package level ParseWithFile for details.
+func (l *Loader) ParseWithFile(config interface{}, filename string) error {
+	previous := l.configFile
+	defer func() { l.configFile = previous }()
+
+	if err := l.SetConfigFile(filename); err != nil {
+		return err
+	}
+	return l.Parse(config)
+}
+
+// SetConfigFile reads the given YAML (or JSON) file as configuration layer
+// for all subsequent calls to Parse and ApplyEnvAndDefaults on the default
+// Loader. See ParseWithFile for details.
+func SetConfigFile(filename string) error {
+	return defaultLoader.SetConfigFile(filename)
+}
+
+// SetConfigFile reads the given YAML (or JSON) file as configuration layer
+// for this Loader. Passing an empty filename removes the layer.
+func (l *Loader) SetConfigFile(filename string) error {
+	if filename == "" {
+		l.configFile = nil
+		return nil
+	}
+
+	data, err := readFile(filename)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err = yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parsing config file: %w", err)
+	}
+
+	l.configFile = raw
+	return nil
+}
+
+// fileKeyName returns the key of the field within a configuration file
+// taken from the `yaml` tag, the `json` tag or the field name. The second
+// return value is false if the field is excluded using "-".
+func fileKeyName(field reflect.StructField) (string, bool) {
+	for _, tag := range []string{"yaml", "json"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		switch name {
+		case "-":
+			return "", false
+		case "":
+			continue
+		default:
+			return name, true
+		}
+	}
+
+	return field.Name, true
+}
+
+// lookupConfigFile fetches the value for the field from the configuration
+// file by walking the file contents along the key path of the field. Keys
+// are matched case-insensitively.
+func (l *Loader) lookupConfigFile(f *field) (string, bool) {
+	if l.configFile == nil || f.fileKey == nil {
+		return "", false
+	}
+
+	var node interface{} = l.configFile
+	for _, key := range f.fileKey {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+
+		if node, ok = lookupFold(m, key); !ok {
+			return "", false
+		}
+	}
+
+	if node == nil {
+		return "", false
+	}
+
+	return formatFileValue(node, sliceDelimiter(f.structField)), true
+}
+
+// lookupFold looks up the key in the map preferring an exact match over a
+// case-insensitive one
+func lookupFold(m map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// formatFileValue renders a value read from a configuration file into the
+// string format understood by setFieldValue: lists are joined using the
+// delimiter, maps are rendered as "key=value" pairs
+func formatFileValue(v interface{}, delimiter string) string {
+	switch val := v.(type) {
+	case []interface{}:
+		elems := make([]string, 0, len(val))
+		for _, e := range val {
+			elems = append(elems, formatFileValue(e, delimiter))
+		}
+		return strings.Join(elems, delimiter)
+
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(val))
+		for k, e := range val {
+			pairs = append(pairs, k+"="+formatFileValue(e, delimiter))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+
+	case time.Time:
+		return formatFileTime(val)
+
+	case nil:
+		return ""
+
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// formatFileTime renders a time read from a configuration file in one of
+// the time parser formats. The TOML parser marks local date-times, dates
+// and times using dedicated locations which are rendered without zone.
+func formatFileTime(t time.Time) string {
+	switch t.Location().String() {
+	case "datetime-local":
+		return t.Format("2006-01-02 15:04:05.999999999")
+	case "date-local":
+		return t.Format("2006-01-02")
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
@@ -0,0 +1,142 @@
+package rconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	fn := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(fn, []byte(content), 0o600))
+	return fn
+}
+
+func TestParseWithFile(t *testing.T) {
+	type testcfg struct {
+		ListenAddr string `yaml:"listen_addr" default:":8080" flag:"listen"`
+		Timeout    time.Duration
+		Peers      []string
+		Weights    []float64 `json:"weights"`
+		Labels     map[string]string
+		Secret     string `yaml:"-" default:"hidden"`
+		Database   struct {
+			Host string `env:"RCONFIG_TEST_DB_HOST"`
+			Port int    `vardefault:"db.port" default:"1"`
+			User string `vardefault:"db.user" default:"nobody"`
+		} `yaml:"db"`
+		Missing string
+	}
+
+	fn := writeTestFile(t, "config.yml", `---
+listen_addr: ":9090"
+timeout: 5s
+peers: [a, b]
+weights: [0.5, 1000000]
+labels:
+  team: core
+secret: visible
+db:
+  host: file-host
+  port: 5432
+`)
+
+	t.Setenv("RCONFIG_TEST_DB_HOST", "env-host")
+
+	cfg := testcfg{Missing: "preset"}
+	l := New()
+	l.SetVariableDefaults(map[string]string{"db.port": "3306", "db.user": "root"})
+	require.NoError(t, l.SetConfigFile(fn))
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, ":9090", cfg.ListenAddr)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"a", "b"}, cfg.Peers)
+	assert.Equal(t, []float64{0.5, 1000000}, cfg.Weights)
+	assert.Equal(t, map[string]string{"team": "core"}, cfg.Labels)
+	assert.Equal(t, "hidden", cfg.Secret)
+	assert.Equal(t, "env-host", cfg.Database.Host, "env must take precedence over file")
+	assert.Equal(t, 5432, cfg.Database.Port, "file must take precedence over vardefault")
+	assert.Equal(t, "root", cfg.Database.User)
+	assert.Equal(t, "preset", cfg.Missing, "untagged field not in file must not be touched")
+
+	cfg = testcfg{}
+	require.NoError(t, l.parse(&cfg, []string{"--listen=:1234"}))
+	assert.Equal(t, ":1234", cfg.ListenAddr, "flag must take precedence over file")
+}
+
+func TestParseWithFileEmbedded(t *testing.T) {
+	type Common struct {
+		LogLevel string `yaml:"log_level"`
+	}
+
+	var cfg struct {
+		Common
+		Name string
+	}
+
+	fn := writeTestFile(t, "config.json", `{"log_level": "debug", "NAME": "app"}`)
+	require.NoError(t, New().ParseWithFile(&cfg, fn))
+
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "app", cfg.Name)
+}
+
+func TestParseWithFileRestoresConfigFile(t *testing.T) {
+	type testcfg struct {
+		Name string `default:"default"`
+	}
+
+	l := New()
+	require.NoError(t, l.SetConfigFile(writeTestFile(t, "base.yml", "name: base\n")))
+
+	cfg := testcfg{}
+	require.NoError(t, l.ParseWithFile(&cfg, writeTestFile(t, "once.yml", "name: once\n")))
+	assert.Equal(t, "once", cfg.Name)
+
+	cfg = testcfg{}
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, "base", cfg.Name, "file must only be used for the ParseWithFile call")
+}
+
+func TestConfigFileApplyEnvAndDefaults(t *testing.T) {
+	var cfg struct {
+		Host string `flag:"host" default:"localhost"`
+		Port int    `flag:"port" default:"80"`
+	}
+
+	fn := writeTestFile(t, "config.yml", "host: file-host\nport: 8080\n")
+
+	l := New()
+	require.NoError(t, l.SetConfigFile(fn))
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{"--port=1"}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	assert.Equal(t, "file-host", cfg.Host)
+	assert.Equal(t, 1, cfg.Port)
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	l := New()
+	assert.Error(t, l.SetConfigFile("/tmp/definitely_not_existing_file_1234567890.yaml"))
+	assert.Error(t, l.SetConfigFile(writeTestFile(t, "invalid.yml", "- a\n- b\n")))
+
+	var cfg struct {
+		Port int
+	}
+	require.NoError(t, l.SetConfigFile(writeTestFile(t, "config.yml", "port: eighty\n")))
+	assert.Error(t, l.parse(&cfg, []string{}))
+
+	require.NoError(t, l.SetConfigFile(""))
+	assert.NoError(t, l.parse(&cfg, []string{}))
+}
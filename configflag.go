@@ -0,0 +1,149 @@
+package rconfig
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// configFlag describes the flag and env variable selecting the file to
+// read variable defaults from
+type configFlag struct {
+	env   string
+	name  string
+	short string
+}
+
+// ConfigFileFlag enables a flag (in the format of the flag tag, for example
+// "config,c") and an env variable to select a file to read variable
+// defaults from. Before parsing the flags the arguments are scanned for the
+// flag (falling back to the env variable), the file is read using
+// VarDefaultsFromFile and its contents take precedence over the variable
+// defaults for this parse. Pass empty strings to disable the flag again.
+func ConfigFileFlag(flag, env string) {
+	defaultLoader.ConfigFileFlag(flag, env)
+}
+
+// ConfigFileFlag enables a flag and an env variable to select a file to
+// read variable defaults from for this Loader. See the package level
+// ConfigFileFlag for details.
+func (l *Loader) ConfigFileFlag(flag, env string) {
+	if flag == "" && env == "" {
+		l.configFlag = nil
+		return
+	}
+
+	name, short, _ := strings.Cut(flag, ",")
+	l.configFlag = &configFlag{env: env, name: name, short: short}
+}
+
+// VarDefaultsFromFile reads variable defaults from the given file choosing
+// the format by its extension: .json, .toml, .ini, .env and .properties are
+// supported, all other files are read as YAML.
+func VarDefaultsFromFile(filename string, opts ...YAMLOption) (map[string]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return VarDefaultsFromJSONFile(filename, opts...)
+	case ".toml":
+		return VarDefaultsFromTOMLFile(filename, opts...)
+	case ".ini":
+		return VarDefaultsFromINIFile(filename, opts...)
+	case ".env":
+		return VarDefaultsFromDotEnvFile(filename, opts...)
+	case ".properties":
+		return VarDefaultsFromPropertiesFile(filename, opts...)
+	default:
+		return VarDefaultsFromYAMLFile(filename, opts...)
+	}
+}
+
+// registerConfigFlag registers the config file flag on the FlagSet so it
+// is accepted by the parser and listed in the usage
+func (l *Loader) registerConfigFlag(fs *pflag.FlagSet) error {
+	if l.configFlag == nil || l.configFlag.name == "" {
+		return nil
+	}
+
+	if fs.Lookup(l.configFlag.name) != nil {
+		return fmt.Errorf("config file flag --%s is already defined", l.configFlag.name)
+	}
+
+	if l.configFlag.short != "" && fs.ShorthandLookup(l.configFlag.short) != nil {
+		return fmt.Errorf("config file flag shorthand -%s is already defined", l.configFlag.short)
+	}
+
+	desc := "File to read variable defaults from"
+	if l.configFlag.env != "" {
+		desc += fmt.Sprintf(" (ENV: %s)", l.configFlag.env)
+	}
+
+	fs.StringP(l.configFlag.name, l.configFlag.short, "", desc)
+	return nil
+}
+
+// scanConfigFlag looks up the config file flag in the arguments. All flags
+// of the config struct are registered on a copy of it, so flags taking a
+// value consume their arguments the same way as in the parse of the full
+// FlagSet. Errors are ignored here as they are reported by that parse.
+func (l *Loader) scanConfigFlag(config interface{}, args []string) string {
+	pre := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	pre.ParseErrorsAllowlist.UnknownFlags = true
+	pre.SetOutput(io.Discard)
+	pre.Usage = func() {}
+
+	// Values of the field flags are written into the copy and thrown away
+	if fields, err := l.collectFields(reflect.New(reflect.TypeOf(config).Elem()).Interface()); err == nil {
+		_ = l.registerFlags(fields, pre)
+	}
+
+	if err := l.registerConfigFlag(pre); err != nil {
+		return ""
+	}
+	_ = pre.Parse(args)
+
+	return pre.Lookup(l.configFlag.name).Value.String()
+}
+
+// loadConfigFlagFile reads the file selected by the config file flag (or
+// its env variable) for the current parse. Its values take precedence over
+// the variable defaults without modifying them. The filename is taken from
+// the already parsed flagSet if given, otherwise the args are scanned for
+// the flags of the config struct.
+func (l *Loader) loadConfigFlagFile(config interface{}, flagSet *pflag.FlagSet, args []string) error {
+	l.configFlagDefaults = nil
+
+	if l.configFlag == nil {
+		return nil
+	}
+
+	var filename string
+	if l.configFlag.name != "" {
+		if flagSet != nil {
+			if flag := flagSet.Lookup(l.configFlag.name); flag != nil {
+				filename = flag.Value.String()
+			}
+		} else {
+			filename = l.scanConfigFlag(config, args)
+		}
+	}
+
+	if filename == "" && l.configFlag.env != "" {
+		filename, _ = l.lookupEnv(l.configFlag.env)
+	}
+
+	if filename == "" {
+		return nil
+	}
+
+	defaults, err := VarDefaultsFromFile(filename)
+	if err != nil {
+		return fmt.Errorf("loading config file %s: %w", filename, err)
+	}
+
+	l.configFlagDefaults = defaults
+	return nil
+}
@@ -0,0 +1,179 @@
+package rconfig
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFileFlag(t *testing.T) {
+	type testcfg struct {
+		Username string `vardefault:"username" default:"unknown" flag:"username"`
+		Port     int    `vardefault:"port" default:"80" env:"RCONFIG_TEST_CONFIGFLAG_PORT" flag:"port,p"`
+		Verbose  bool   `flag:"verbose,v"`
+	}
+
+	yamlFile := writeTestFile(t, "app.yml", "username: yaml\nport: 8080\n")
+	jsonFile := writeTestFile(t, "app.json", `{"username": "json"}`)
+
+	for name, tc := range map[string]struct {
+		args     []string
+		env      map[string]string
+		username string
+		port     int
+	}{
+		"no file":            {args: []string{}, username: "unknown", port: 80},
+		"long flag":          {args: []string{"--config", yamlFile}, username: "yaml", port: 8080},
+		"long flag equals":   {args: []string{"-v", "--config=" + jsonFile, "pos"}, username: "json", port: 80},
+		"shorthand":          {args: []string{"--port", "1", "-c", yamlFile}, username: "yaml", port: 1},
+		"env":                {args: []string{}, env: map[string]string{"RCONFIG_TEST_CONFIG": yamlFile}, username: "yaml", port: 8080},
+		"flag wins over env": {args: []string{"-c", jsonFile}, env: map[string]string{"RCONFIG_TEST_CONFIG": yamlFile}, username: "json", port: 80},
+		"env wins over file": {
+			args:     []string{"-c", yamlFile},
+			env:      map[string]string{"RCONFIG_TEST_CONFIGFLAG_PORT": "9090"},
+			username: "yaml",
+			port:     9090,
+		},
+		"after terminator": {args: []string{"--", "--config", yamlFile}, username: "unknown", port: 80},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			l := New()
+			l.ConfigFileFlag("config,c", "RCONFIG_TEST_CONFIG")
+
+			var cfg testcfg
+			require.NoError(t, l.parse(&cfg, tc.args))
+			assert.Equal(t, tc.username, cfg.Username)
+			assert.Equal(t, tc.port, cfg.Port)
+
+			flag := l.fs.Lookup("config")
+			require.NotNil(t, flag)
+			assert.Equal(t, "c", flag.Shorthand)
+			assert.Contains(t, flag.Usage, "(ENV: RCONFIG_TEST_CONFIG)")
+		})
+	}
+}
+
+func TestConfigFileFlagRegisterFlags(t *testing.T) {
+	var cfg struct {
+		Username string `vardefault:"username" default:"unknown" flag:"username"`
+	}
+
+	l := New()
+	l.ConfigFileFlag("config", "")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{"--config", writeTestFile(t, "app.toml", "username = \"toml\"\n")}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	assert.Equal(t, "toml", cfg.Username)
+}
+
+func TestConfigFileFlagErrors(t *testing.T) {
+	l := New()
+	l.ConfigFileFlag("config,c", "")
+	assert.Error(t, l.parse(&struct{}{}, []string{"--config", "/does/not/exist.yml"}), "missing file")
+	assert.Error(t, l.parse(&struct {
+		A string `flag:"config"`
+	}{}, []string{}), "flag collision")
+	assert.Error(t, l.parse(&struct {
+		A string `flag:"cluster,c"`
+	}{}, []string{}), "shorthand collision")
+
+	l.ConfigFileFlag("", "")
+	assert.NoError(t, l.parse(&struct {
+		A string `flag:"config"`
+	}{}, []string{}), "disabled flag")
+}
+
+func TestVarDefaultsFromFile(t *testing.T) {
+	for name, content := range map[string]string{
+		"app.yaml":       "key: value\n",
+		"app.JSON":       `{"key": "value"}`,
+		"app.toml":       "key = \"value\"\n",
+		"app.ini":        "key = value\n",
+		"app.env":        "key=value\n",
+		"app.properties": "key=value\n",
+		"app.conf":       "key: value\n",
+	} {
+		defaults, err := VarDefaultsFromFile(writeTestFile(t, name, content))
+		require.NoError(t, err, name)
+		assert.Equal(t, map[string]string{"key": "value"}, defaults, name)
+	}
+}
+
+func TestConfigFileFlagRepeatedParse(t *testing.T) {
+	type testcfg struct {
+		Port int    `vardefault:"port" default:"80"`
+		Name string `vardefault:"name"`
+	}
+
+	defaults := map[string]string{"name": "defaults", "port": "1"}
+
+	l := New()
+	l.ConfigFileFlag("config", "")
+	l.SetVariableDefaults(defaults)
+
+	var cfg testcfg
+	require.NoError(t, l.parse(&cfg, []string{"--config", writeTestFile(t, "a.yml", "port: 9\n")}))
+	assert.Equal(t, 9, cfg.Port)
+	assert.Equal(t, "defaults", cfg.Name)
+
+	var cfg2 testcfg
+	require.NoError(t, l.parse(&cfg2, []string{}))
+	assert.Equal(t, 1, cfg2.Port, "file values must not persist into the next parse")
+	assert.Equal(t, "defaults", cfg2.Name)
+
+	assert.Equal(t, map[string]string{"name": "defaults", "port": "1"}, defaults, "variable defaults must not be modified")
+}
+
+func TestConfigFileFlagLists(t *testing.T) {
+	var cfg struct {
+		Hosts []string `vardefault:"hosts"`
+		Ports []int    `vardefault:"ports"`
+	}
+
+	l := New()
+	l.ConfigFileFlag("config", "")
+	l.SetVariableDefaults(map[string]string{
+		"hosts": "a,b,c", "hosts.0": "a", "hosts.1": "b", "hosts.2": "c",
+		"ports": "1,2", "ports.0": "1", "ports.1": "2",
+	})
+
+	fn := writeTestFile(t, "app.yml", "hosts: [x]\n")
+	require.NoError(t, l.parse(&cfg, []string{"--config", fn}))
+	assert.Equal(t, []string{"x"}, cfg.Hosts, "list must be taken from the config file only")
+	assert.Equal(t, []int{1, 2}, cfg.Ports)
+
+	fn = writeTestFile(t, "app.env", "hosts=x,y\n")
+	require.NoError(t, l.parse(&cfg, []string{"--config", fn}))
+	assert.Equal(t, []string{"x", "y"}, cfg.Hosts, "joined list must win over indexed keys of lower layers")
+}
+
+func TestConfigFileFlagValueArguments(t *testing.T) {
+	var cfg struct {
+		Name     string `flag:"name" vardefault:"name" default:"unknown"`
+		Username string `vardefault:"username" default:"unknown"`
+		Verbose  bool   `flag:"verbose,v"`
+	}
+
+	fn := writeTestFile(t, "app.yml", "username: yaml\n")
+
+	l := New()
+	l.ConfigFileFlag("config,c", "")
+	require.NoError(t, l.parse(&cfg, []string{"--name", "-c", fn}))
+	assert.Equal(t, "-c", cfg.Name)
+	assert.Equal(t, "unknown", cfg.Username, "value of another flag must not select the config file")
+	assert.Equal(t, []string{fn}, l.Args())
+
+	cfg.Name, cfg.Username = "", ""
+	require.NoError(t, l.parse(&cfg, []string{"-v", "-c", fn}))
+	assert.Equal(t, "yaml", cfg.Username)
+	assert.True(t, cfg.Verbose)
+}
@@ -0,0 +1,103 @@
+package rconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogLevel int
+
+func (t *testLogLevel) UnmarshalText(text []byte) error {
+	for i, name := range []string{"debug", "info", "warn", "error"} {
+		if strings.EqualFold(string(text), name) {
+			*t = testLogLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level %q", text)
+}
+
+func (t testLogLevel) MarshalText() ([]byte, error) {
+	return []byte([]string{"debug", "info", "warn", "error"}[t]), nil
+}
+
+type testHostname string
+
+func (t *testHostname) String() string { return string(*t) }
+func (*testHostname) Type() string     { return "hostname" }
+
+func (t *testHostname) Set(v string) error {
+	if v == "" || strings.Contains(v, " ") {
+		return errors.New("invalid hostname")
+	}
+	*t = testHostname(strings.ToLower(v))
+	return nil
+}
+
+func TestCustomTypeParsing(t *testing.T) {
+	var cfg struct {
+		Level     testLogLevel  `default:"info" flag:"level"`
+		EnvLevel  testLogLevel  `env:"RCONFIG_TEST_LEVEL"`
+		VarLevel  *testLogLevel `vardefault:"level"`
+		Host      testHostname  `default:"localhost" flag:"host,H"`
+		IP        net.IP        `default:"127.0.0.1" flag:"ip"`
+		NoneLevel testLogLevel
+	}
+
+	t.Setenv("RCONFIG_TEST_LEVEL", "WARN")
+
+	l := New()
+	l.SetVariableDefaults(map[string]string{"level": "error"})
+	require.NoError(t, l.parse(&cfg, []string{"--level=debug", "-H", "Example.COM"}))
+
+	assert.Equal(t, testLogLevel(0), cfg.Level)
+	assert.Equal(t, testLogLevel(2), cfg.EnvLevel)
+	require.NotNil(t, cfg.VarLevel)
+	assert.Equal(t, testLogLevel(3), *cfg.VarLevel)
+	assert.Equal(t, testHostname("example.com"), cfg.Host)
+	assert.Equal(t, net.ParseIP("127.0.0.1"), cfg.IP)
+	assert.Equal(t, testLogLevel(0), cfg.NoneLevel)
+
+	// Defaults are shown in their text representation
+	assert.Equal(t, "info", l.fs.Lookup("level").DefValue)
+	assert.Equal(t, "hostname", l.fs.Lookup("host").Value.Type())
+}
+
+func TestCustomTypeApplyEnvAndDefaults(t *testing.T) {
+	var cfg struct {
+		Level testLogLevel `default:"info" env:"RCONFIG_TEST_LEVEL" flag:"level"`
+		Host  testHostname `default:"localhost" env:"RCONFIG_TEST_HOST" flag:"host"`
+		Other testLogLevel `env:"RCONFIG_TEST_OTHER_LEVEL"`
+	}
+
+	t.Setenv("RCONFIG_TEST_LEVEL", "error")
+	t.Setenv("RCONFIG_TEST_HOST", "env.example.com")
+	t.Setenv("RCONFIG_TEST_OTHER_LEVEL", "warn")
+
+	l := New()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{"--host=cli.example.com"}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	assert.Equal(t, testLogLevel(3), cfg.Level)
+	assert.Equal(t, testHostname("cli.example.com"), cfg.Host)
+	assert.Equal(t, testLogLevel(2), cfg.Other)
+}
+
+func TestCustomTypeErrors(t *testing.T) {
+	assert.Error(t, New().parse(&struct {
+		Level testLogLevel `default:"verbose"`
+	}{}, []string{}))
+
+	assert.Error(t, New().parse(&struct {
+		Host testHostname `default:"not a hostname"`
+	}{}, []string{}))
+}
@@ -0,0 +1,61 @@
+package rconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DecoderFunc converts the string representation of a value into the
+// type it was registered for using RegisterDecoder
+type DecoderFunc func(string) (interface{}, error)
+
+// registeredDecoders holds the decoders registered using the package level
+// RegisterDecoder which are copied into every Loader created by New
+var registeredDecoders = make(map[reflect.Type]DecoderFunc)
+
+// RegisterDecoder teaches the parser how to decode values of the given
+// type which cannot be extended with an UnmarshalText method, for example
+// types from third-party packages. The decoder is used for defaults, ENV,
+// variable defaults and flags and takes precedence over all built-in
+// parsing logic for that type.
+//
+// The decoder is registered in the default Loader and in all Loaders
+// created by New afterwards, so it should be registered before creating
+// Loaders, for example in an init function.
+func RegisterDecoder(t reflect.Type, dec DecoderFunc) {
+	registeredDecoders[t] = dec
+	defaultLoader.RegisterDecoder(t, dec)
+}
+
+// RegisterDecoder registers a decoder for the given type in this Loader
+// only. See the package level RegisterDecoder for details.
+func (l *Loader) RegisterDecoder(t reflect.Type, dec DecoderFunc) {
+	l.decoders[t] = dec
+}
+
+func (*Loader) decode(field reflect.Value, dec DecoderFunc, value string) error {
+	v, err := dec(value)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", field.Type(), err)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch {
+	case !rv.IsValid():
+		field.Set(reflect.Zero(field.Type()))
+
+	case rv.Type().AssignableTo(field.Type()):
+		field.Set(rv)
+
+	case rv.Kind() == field.Kind() && rv.Type().ConvertibleTo(field.Type()):
+		// Only named types sharing the representation (for example a
+		// string returned for a string based type) are converted, other
+		// conversions would silently change the value
+		field.Set(rv.Convert(field.Type()))
+
+	default:
+		return fmt.Errorf("decoder for %s returned incompatible type %s", field.Type(), rv.Type())
+	}
+
+	return nil
+}
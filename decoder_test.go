@@ -0,0 +1,137 @@
+package rconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPoint simulates a third-party type without any parsing methods
+type testPoint struct {
+	X, Y int
+}
+
+func decodeTestPoint(s string) (interface{}, error) {
+	var p testPoint
+	if _, err := fmt.Sscanf(s, "%dx%d", &p.X, &p.Y); err != nil {
+		return nil, errors.New("point must be formatted as XxY")
+	}
+	return p, nil
+}
+
+func TestDecoderRegistry(t *testing.T) {
+	var cfg struct {
+		Origin  testPoint            `default:"0x0" flag:"origin"`
+		Target  testPoint            `env:"RCONFIG_TEST_TARGET"`
+		Size    *testPoint           `vardefault:"size"`
+		Named   map[string]testPoint `default:"a=1x2,b=3x4"`
+		Missing *testPoint           `flag:"missing"`
+	}
+
+	t.Setenv("RCONFIG_TEST_TARGET", "10x20")
+
+	l := New()
+	l.RegisterDecoder(reflect.TypeOf(testPoint{}), decodeTestPoint)
+	l.SetVariableDefaults(map[string]string{"size": "640x480"})
+	require.NoError(t, l.parse(&cfg, []string{"--origin=5x6"}))
+
+	assert.Equal(t, testPoint{5, 6}, cfg.Origin)
+	assert.Equal(t, testPoint{10, 20}, cfg.Target)
+	require.NotNil(t, cfg.Size)
+	assert.Equal(t, testPoint{640, 480}, *cfg.Size)
+	assert.Equal(t, map[string]testPoint{"a": {1, 2}, "b": {3, 4}}, cfg.Named)
+	assert.Nil(t, cfg.Missing)
+	assert.Equal(t, "testPoint", l.fs.Lookup("origin").Value.Type())
+}
+
+func TestDecoderApplyEnvAndDefaults(t *testing.T) {
+	var cfg struct {
+		Origin testPoint `default:"1x1" env:"RCONFIG_TEST_ORIGIN" flag:"origin"`
+		Target testPoint `env:"RCONFIG_TEST_TARGET"`
+	}
+
+	t.Setenv("RCONFIG_TEST_ORIGIN", "2x2")
+	t.Setenv("RCONFIG_TEST_TARGET", "3x3")
+
+	l := New()
+	l.RegisterDecoder(reflect.TypeOf(testPoint{}), decodeTestPoint)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	assert.Equal(t, testPoint{2, 2}, cfg.Origin)
+	assert.Equal(t, testPoint{3, 3}, cfg.Target)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	type testcfg struct {
+		Origin testPoint `default:"invalid"`
+	}
+
+	l := New()
+	l.RegisterDecoder(reflect.TypeOf(testPoint{}), decodeTestPoint)
+	assert.Error(t, l.parse(&testcfg{}, []string{}))
+
+	l.RegisterDecoder(reflect.TypeOf(testPoint{}), func(string) (interface{}, error) { return "foo", nil })
+	assert.Error(t, l.parse(&testcfg{}, []string{}), "incompatible type must be reported")
+
+	type label string
+	type lossycfg struct {
+		Name  label `default:"x"`
+		Count int   `default:"1"`
+	}
+
+	l = New()
+	l.RegisterDecoder(reflect.TypeOf(label("")), func(string) (interface{}, error) { return 65, nil })
+	assert.Error(t, l.parse(&lossycfg{}, []string{}), "int must not be converted into a string")
+
+	l = New()
+	l.RegisterDecoder(reflect.TypeOf(0), func(string) (interface{}, error) { return 1.5, nil })
+	assert.Error(t, l.parse(&lossycfg{}, []string{}), "float must not be truncated into an int")
+
+	l = New()
+	l.RegisterDecoder(reflect.TypeOf(label("")), func(s string) (interface{}, error) { return s, nil })
+	cfg := lossycfg{}
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, label("x"), cfg.Name, "types sharing the kind must be converted")
+
+	// Without decoder the struct is walked like a sub-struct
+	require.NoError(t, New().parse(&testcfg{}, []string{}))
+}
+
+func TestDecoderPackageLevel(t *testing.T) {
+	type greeting struct {
+		Text string
+	}
+
+	gt := reflect.TypeOf(greeting{})
+	before := New()
+
+	RegisterDecoder(gt, func(s string) (interface{}, error) { return greeting{Text: s}, nil })
+	t.Cleanup(func() {
+		delete(registeredDecoders, gt)
+		delete(defaultLoader.decoders, gt)
+	})
+
+	var cfg struct {
+		Greeting greeting `default:"hi"`
+	}
+
+	require.NoError(t, New().parse(&cfg, []string{}))
+	assert.Equal(t, greeting{Text: "hi"}, cfg.Greeting)
+
+	cfg.Greeting = greeting{}
+	require.NoError(t, parse(&cfg, []string{}))
+	assert.Equal(t, greeting{Text: "hi"}, cfg.Greeting)
+
+	cfg.Greeting = greeting{}
+	require.NoError(t, before.parse(&cfg, []string{}))
+	assert.Equal(t, greeting{}, cfg.Greeting, "loaders created before the registration must not be affected")
+}
@@ -0,0 +1,224 @@
+package rconfig
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadDotEnvFile reads contents of a file and calls ReadDotEnv
+func ReadDotEnvFile(filename string) (map[string]string, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return ReadDotEnv(data)
+}
+
+// ReadDotEnv parses the contents of a dotenv (.env) file into a map of
+// variables without modifying the process environment. Supported are:
+//
+//   - comments (lines starting with `#` and ` #` after unquoted values)
+//   - an optional `export` prefix
+//   - single quoted values taken literally
+//   - double quoted values with escape sequences (`\n`, `\t`, `\"`, …)
+//     which may span multiple lines
+//   - references to variables as `${VAR}` or `$VAR` in unquoted and double
+//     quoted values, resolved from the variables defined before within the
+//     file and the process environment
+//
+// Use the result with SetEnvOverlay to feed the values into the `env` tags
+// or with SetVariableDefaults to use them as variable defaults.
+func ReadDotEnv(in []byte) (map[string]string, error) {
+	var (
+		env   = make(map[string]string)
+		lines = strings.Split(strings.ReplaceAll(string(in), "\r\n", "\n"), "\n")
+	)
+
+	lookup := func(name string) string {
+		if v, ok := env[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	}
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(line, "export "); ok {
+			line = strings.TrimSpace(rest)
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("parsing dotenv: line %d: expected KEY=value", lineNo)
+		}
+
+		value = strings.TrimLeft(value, " \t")
+
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = value[:idx]
+			}
+			env[key] = expandDotEnv(strings.TrimSpace(value), false, lookup)
+			continue
+		}
+
+		// Quoted values might span multiple lines: collect until the closing quote
+		quote := value[0]
+		raw := value[1:]
+		end := findClosingQuote(raw, quote)
+		for end < 0 {
+			if i+1 >= len(lines) {
+				return nil, fmt.Errorf("parsing dotenv: line %d: unterminated quoted value", lineNo)
+			}
+			i++
+			raw += "\n" + lines[i]
+			end = findClosingQuote(raw, quote)
+		}
+
+		if trailing := strings.TrimSpace(raw[end+1:]); trailing != "" && !strings.HasPrefix(trailing, "#") {
+			return nil, fmt.Errorf("parsing dotenv: line %d: unexpected content after quoted value", lineNo)
+		}
+
+		if quote == '\'' {
+			env[key] = raw[:end]
+		} else {
+			env[key] = expandDotEnv(raw[:end], true, lookup)
+		}
+	}
+
+	return env, nil
+}
+
+// VarDefaultsFromDotEnvFile reads contents of a file and calls VarDefaultsFromDotEnv
+func VarDefaultsFromDotEnvFile(filename string, opts ...YAMLOption) (map[string]string, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return VarDefaultsFromDotEnv(data, opts...)
+}
+
+// VarDefaultsFromDotEnv creates a vardefaults map from the contents of a
+// dotenv (.env) file as parsed by ReadDotEnv
+func VarDefaultsFromDotEnv(in []byte, opts ...YAMLOption) (map[string]string, error) {
+	options := &YAMLOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	env, err := ReadDotEnv(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.KeyToLower {
+		return env, nil
+	}
+
+	flat := make(map[string]string, len(env))
+	for k, v := range env {
+		flat[strings.ToLower(k)] = v
+	}
+	return flat, nil
+}
+
+// findClosingQuote returns the index of the first unescaped quote in the
+// string or -1 if there is none
+func findClosingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// expandDotEnv replaces variable references in the value and, if enabled,
+// resolves escape sequences as used in double quoted values
+func expandDotEnv(value string, escapes bool, lookup func(string) string) string {
+	var out strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case c == '\\' && escapes && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				out.WriteByte('\n')
+			case 'r':
+				out.WriteByte('\r')
+			case 't':
+				out.WriteByte('\t')
+			default:
+				// Covers \\, \", \$ and unknown sequences
+				out.WriteByte(value[i])
+			}
+
+		case c == '$' && i+1 < len(value) && value[i+1] == '{':
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				out.WriteString(value[i:])
+				return out.String()
+			}
+			out.WriteString(lookup(value[i+2 : i+end]))
+			i += end
+
+		case c == '$' && i+1 < len(value) && isEnvNameChar(value[i+1]):
+			end := i + 1
+			for end < len(value) && isEnvNameChar(value[end]) {
+				end++
+			}
+			out.WriteString(lookup(value[i+1 : end]))
+			i = end - 1
+
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.String()
+}
+
+func isEnvNameChar(c byte) bool {
+	return c == '_' || charGroupUpperLetter.Contains(rune(c)) ||
+		charGroupLowerLetter.Contains(rune(c)) || charGroupNumber.Contains(rune(c))
+}
+
+// SetEnvOverlay presets the parser with a set of env variables (for example
+// read by ReadDotEnvFile) which are used for the `env` tags when the
+// variable is not set in the process environment. The process environment
+// itself is not modified.
+func SetEnvOverlay(env map[string]string) {
+	defaultLoader.SetEnvOverlay(env)
+}
+
+// SetEnvOverlay presets this Loader with a set of env variables used when
+// the variable is not set in the process environment
+func (l *Loader) SetEnvOverlay(env map[string]string) {
+	l.envOverlay = env
+}
+
+// lookupEnv looks up the variable in the process environment and falls
+// back to the env overlay
+func (l *Loader) lookupEnv(name string) (string, bool) {
+	// Use LookupEnv to distinguish between unset and empty
+	if v, ok := os.LookupEnv(name); ok {
+		return v, true
+	}
+
+	v, ok := l.envOverlay[name]
+	return v, ok
+}
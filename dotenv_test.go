@@ -0,0 +1,104 @@
+package rconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDotEnv(t *testing.T) {
+	t.Setenv("RCONFIG_TEST_DOTENV_HOME", "/home/test")
+
+	env, err := ReadDotEnv([]byte(`# Local development settings
+PLAIN=value
+SPACED = spaced value   # trailing comment
+export EXPORTED=yes
+SINGLE='literal ${PLAIN} \n'
+DOUBLE="escaped \"quote\"\tand\nnewline"
+MULTI="first line
+second line"
+REF=${PLAIN}-$PLAIN
+HOME_DIR=${RCONFIG_TEST_DOTENV_HOME}/app
+ESCAPED_REF="\${PLAIN}"
+EMPTY=
+HASH=a#b
+QUOTED_COMMENT="value" # comment
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"PLAIN":          "value",
+		"SPACED":         "spaced value",
+		"EXPORTED":       "yes",
+		"SINGLE":         `literal ${PLAIN} \n`,
+		"DOUBLE":         "escaped \"quote\"\tand\nnewline",
+		"MULTI":          "first line\nsecond line",
+		"REF":            "value-value",
+		"HOME_DIR":       "/home/test/app",
+		"ESCAPED_REF":    "${PLAIN}",
+		"EMPTY":          "",
+		"HASH":           "a#b",
+		"QUOTED_COMMENT": "value",
+	}, env)
+}
+
+func TestReadDotEnv_Errors(t *testing.T) {
+	for name, data := range map[string]string{
+		"missing separator":   "FOO\n",
+		"space in key":        "FOO BAR=baz\n",
+		"unterminated quote":  "FOO=\"bar\nBAZ=1\n",
+		"content after quote": "FOO=\"bar\" baz\n",
+	} {
+		_, err := ReadDotEnv([]byte(data))
+		assert.ErrorContains(t, err, "parsing dotenv", name)
+	}
+}
+
+func TestDotEnvOverlay(t *testing.T) {
+	var cfg struct {
+		Host string `env:"RCONFIG_TEST_DOTENV_HOST" default:"localhost"`
+		Port int    `env:"RCONFIG_TEST_DOTENV_PORT" default:"80"`
+		User string `env:"RCONFIG_TEST_DOTENV_USER" default:"nobody"`
+	}
+
+	fn := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(fn, []byte("RCONFIG_TEST_DOTENV_HOST=dotenv-host\nRCONFIG_TEST_DOTENV_PORT=8080\n"), 0o600))
+
+	t.Setenv("RCONFIG_TEST_DOTENV_PORT", "9090")
+
+	env, err := ReadDotEnvFile(fn)
+	require.NoError(t, err)
+
+	l := New()
+	l.SetEnvOverlay(env)
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, "dotenv-host", cfg.Host)
+	assert.Equal(t, 9090, cfg.Port, "process environment must take precedence")
+	assert.Equal(t, "nobody", cfg.User)
+
+	_, ok := os.LookupEnv("RCONFIG_TEST_DOTENV_HOST")
+	assert.False(t, ok, "process environment must not be modified")
+}
+
+func TestVarDefaultsFromDotEnv(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(fn, []byte("DB_HOST=db\nexport DB_PORT=5432\n"), 0o600))
+
+	defaults, err := VarDefaultsFromDotEnvFile(fn)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_HOST": "db", "DB_PORT": "5432"}, defaults)
+
+	defaults, err = VarDefaultsFromDotEnvFile(fn, WithKeyToLower())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db_host": "db", "db_port": "5432"}, defaults)
+
+	_, err = VarDefaultsFromDotEnvFile("/tmp/definitely_not_existing_file_1234567890.env")
+	assert.Error(t, err)
+
+	_, err = ReadDotEnvFile("/tmp/definitely_not_existing_file_1234567890.env")
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package rconfig
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// checkDuplicates ensures no flag name, flag shorthand or env variable is
+// used by more than one field. pflag would panic on duplicate flags and
+// duplicate env variables would silently feed the same value into multiple
+// fields.
+func checkDuplicates(fields []*field) error {
+	var (
+		envs   = map[string]*field{}
+		flags  = map[string]*field{}
+		shorts = map[string]*field{}
+	)
+
+	for _, f := range fields {
+		if f.flag != "" {
+			if other, ok := flags[f.flag]; ok {
+				return fmt.Errorf("fields %s and %s both use flag --%s", other.name(), f.name(), f.flag)
+			}
+			flags[f.flag] = f
+		}
+
+		if f.flagShort != "" {
+			if other, ok := shorts[f.flagShort]; ok {
+				return fmt.Errorf("fields %s and %s both use flag shorthand -%s", other.name(), f.name(), f.flagShort)
+			}
+			shorts[f.flagShort] = f
+		}
+
+		if f.env != "" {
+			if other, ok := envs[f.env]; ok {
+				return fmt.Errorf("fields %s and %s both use env variable %s", other.name(), f.name(), f.env)
+			}
+			envs[f.env] = f
+		}
+	}
+
+	return nil
+}
+
+// checkFlagsAvailable ensures none of the flags of the fields is already
+// defined in the FlagSet
+func checkFlagsAvailable(fields []*field, fs *pflag.FlagSet) error {
+	for _, f := range fields {
+		if f.flag != "" && fs.Lookup(f.flag) != nil {
+			return fmt.Errorf("flag --%s of field %s is already defined", f.flag, f.name())
+		}
+
+		if f.flagShort != "" && fs.ShorthandLookup(f.flagShort) != nil {
+			return fmt.Errorf("flag shorthand -%s of field %s is already defined", f.flagShort, f.name())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package rconfig
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateNames(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cfg    interface{}
+		expect string
+	}{
+		"flag in nested structs": {
+			cfg: &struct {
+				Server struct {
+					Port int `flag:"port"`
+				}
+				Metrics struct {
+					Port int `flag:"port"`
+				}
+			}{},
+			expect: "fields Server.Port and Metrics.Port both use flag --port",
+		},
+		"flag shorthand": {
+			cfg: &struct {
+				Verbose bool `flag:"verbose,v"`
+				Version bool `flag:"version,v"`
+			}{},
+			expect: "fields Verbose and Version both use flag shorthand -v",
+		},
+		"env variable": {
+			cfg: &struct {
+				A string `env:"FOO"`
+				B string `env:"FOO"`
+			}{},
+			expect: "fields A and B both use env variable FOO",
+		},
+		"prefixed flag": {
+			cfg: &struct {
+				Port    int `flag:"replica-port"`
+				Replica struct {
+					Port int `flag:"port"`
+				} `prefix:"replica"`
+			}{},
+			expect: "fields Port and Replica.Port both use flag --replica-port",
+		},
+	} {
+		err := New().parse(tc.cfg, []string{})
+		require.Error(t, err, name)
+		assert.Equal(t, tc.expect, err.Error(), name)
+
+		err = New().RegisterFlags(tc.cfg, pflag.NewFlagSet("test", pflag.ContinueOnError))
+		require.Error(t, err, name)
+		assert.Equal(t, tc.expect, err.Error(), name)
+	}
+}
+
+func TestDuplicateNamesWithExistingFlags(t *testing.T) {
+	var cfg struct {
+		Port    int  `flag:"port"`
+		Verbose bool `flag:"verbose,v"`
+	}
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Int("port", 0, "")
+	assert.EqualError(t, New().RegisterFlags(&cfg, fs), "flag --port of field Port is already defined")
+
+	fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.BoolP("version", "v", false, "")
+	assert.EqualError(t, New().RegisterFlags(&cfg, fs), "flag shorthand -v of field Verbose is already defined")
+	assert.Nil(t, fs.Lookup("port"), "no flag must be registered on error")
+}
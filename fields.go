@@ -0,0 +1,426 @@
+package rconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// field describes a single configurable field of the configuration
+// struct together with the sources it is bound to. Both Parse and the
+// RegisterFlags / ApplyEnvAndDefaults integration work on these, so the
+// precedence of the sources is identical for all entry points.
+type field struct {
+	env         string
+	fileKey     []string
+	flag        string
+	flagShort   string
+	path        []string
+	structField reflect.StructField
+	tagged      bool
+	value       reflect.Value
+	varDefault  string
+	// varDefaultFold is set for keys derived by AutoVarDefault which are
+	// matched case-insensitively like the keys of a configuration file
+	varDefaultFold bool
+}
+
+// name returns the path of the field within the configuration struct
+// (for example "Database.Host")
+func (f *field) name() string {
+	return strings.Join(f.path, ".")
+}
+
+// collectFields walks the configuration struct and returns all fields
+// managed by rconfig. Sub-structs are walked recursively.
+func (l *Loader) collectFields(in interface{}) ([]*field, error) {
+	if reflect.TypeOf(in).Kind() != reflect.Ptr {
+		return nil, errors.New("calling parser with non-pointer")
+	}
+
+	if reflect.ValueOf(in).Elem().Kind() != reflect.Struct {
+		return nil, errors.New("calling parser with pointer to non-struct")
+	}
+
+	fields := l.walkStruct(reflect.ValueOf(in).Elem(), nil, []string{}, namePrefix{})
+	if err := checkDuplicates(fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// namePrefix holds the prefixes applied to the names of all fields within
+// a sub-struct carrying a `prefix` tag
+type namePrefix struct {
+	env        string
+	flag       string
+	varDefault string
+
+	// path holds the prefix tags or names of the sub-structs leading to
+	// the field, tags only the prefix tags. Both are passed to the naming
+	// strategies to derive names.
+	path []string
+	tags []string
+}
+
+// extend returns the prefixes for a sub-struct tagged with the given prefix
+// within the current one: "replica" adds "replica-" to flags, "REPLICA_"
+// to env variables and "replica." to vardefault keys. These prefixes are
+// applied to names given in tags and intentionally do not use the naming
+// strategies, derived names are built from the path and tags instead.
+func (p namePrefix) extend(prefix string) namePrefix {
+	if prefix == "" {
+		return p
+	}
+
+	return namePrefix{
+		env:        p.env + deriveEnvVarName(prefix) + "_",
+		flag:       p.flag + prefix + "-",
+		varDefault: p.varDefault + prefix + ".",
+		path:       p.path,
+		tags:       append(append([]string{}, p.tags...), prefix),
+	}
+}
+
+// nest returns the prefixes for the sub-struct field: its prefix tag or,
+// without one, its name is added to the path used by AutoEnvNested and
+// AutoFlag.
+// Embedded structs without prefix tag do not add to the path as their
+// fields are promoted into the parent.
+func (p namePrefix) nest(sf reflect.StructField) namePrefix {
+	tag := sf.Tag.Get("prefix")
+	sub := p.extend(tag)
+
+	switch {
+	case tag != "":
+		sub.path = append(append([]string{}, p.path...), tag)
+	case !sf.Anonymous:
+		sub.path = append(append([]string{}, p.path...), sf.Name)
+	}
+
+	return sub
+}
+
+// walkStruct collects the fields of the struct. The path holds the names of
+// the fields leading to the struct, the fileKey the corresponding keys
+// within a configuration file (nil if the struct is excluded from it).
+func (l *Loader) walkStruct(st reflect.Value, path, fileKey []string, prefix namePrefix) []*field {
+	var fields []*field
+
+	for i := 0; i < st.NumField(); i++ {
+		valField := st.Field(i)
+		typeField := st.Type().Field(i)
+
+		if !typeField.IsExported() {
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), typeField.Name)
+
+		var fieldFileKey []string
+		if key, ok := fileKeyName(typeField); ok && fileKey != nil {
+			fieldFileKey = append(append([]string{}, fileKey...), key)
+		}
+
+		if l.isSubStruct(typeField.Type) {
+			if typeField.Anonymous && fieldFileKey != nil && typeField.Tag.Get("yaml") == "" && typeField.Tag.Get("json") == "" {
+				// Embedded structs are inlined into their parent
+				fieldFileKey = fileKey
+			}
+			fields = append(fields, l.walkStruct(valField, fieldPath, fieldFileKey, prefix.nest(typeField))...)
+			continue
+		}
+
+		tagged := hasConfigTag(typeField)
+		if !tagged && !l.autoEnv && !l.autoFlag && ((l.configFile == nil && !l.autoVarDefault) || fieldFileKey == nil) {
+			// None of our supported tags is present and no source applies
+			continue
+		}
+
+		if !tagged && !l.isSupportedType(typeField.Type) {
+			// Names derived for untagged fields must not expose types
+			// which cannot be parsed (functions, channels, ...)
+			continue
+		}
+
+		f := &field{
+			fileKey:     fieldFileKey,
+			path:        fieldPath,
+			structField: typeField,
+			tagged:      tagged,
+			value:       valField,
+		}
+
+		switch env := typeField.Tag.Get("env"); {
+		case env != "":
+			f.env = prefix.env + env
+			if l.envPrefixExplicit {
+				f.env = l.envPrefix + f.env
+			}
+
+		case l.autoEnv && l.autoEnvNested:
+			f.env = l.envPrefix + l.envNaming.Name(append(prefix.path, typeField.Name))
+
+		case l.autoEnv:
+			f.env = l.envPrefix + l.envNaming.Name(append(prefix.tags, typeField.Name))
+		}
+
+		switch flag := typeField.Tag.Get("flag"); {
+		case flag == "-":
+			// Field opted out of AutoFlag
+
+		case flag != "":
+			f.flag, f.flagShort, _ = strings.Cut(flag, ",")
+			f.flag = prefix.flag + f.flag
+			if prefix.flag != "" {
+				// Shorthands cannot be prefixed and would collide when the
+				// sub-struct is used multiple times
+				f.flagShort = ""
+			}
+
+		case l.autoFlag && l.isSupportedType(typeField.Type):
+			f.flag = l.flagNaming.Name(append(prefix.path, typeField.Name))
+		}
+
+		switch key := typeField.Tag.Get("vardefault"); {
+		case key != "":
+			f.varDefault = prefix.varDefault + key
+
+		case l.autoVarDefault && fieldFileKey != nil:
+			f.varDefault = l.varDefaultRoot + l.varDefaultNaming.Name(fieldFileKey)
+			f.varDefaultFold = true
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// hasConfigTag reports whether any of the struct tags controlling the
+// sources of a field is present
+func hasConfigTag(field reflect.StructField) bool {
+	for _, tag := range []string{"default", "env", "flag", "vardefault"} {
+		if v, ok := field.Tag.Lookup(tag); ok && (tag != "flag" || v != "-") {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubStruct reports whether the type is a struct to be walked instead of
+// a value to be parsed
+func (l *Loader) isSubStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) && !l.isCustomType(t)
+}
+
+// applyValues sets all fields to the values resolved from ENV, variable
+// defaults and default tags
+func (l *Loader) applyValues(fields []*field) error {
+	for _, f := range fields {
+		if err := l.applyValue(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyValue sets the field to the value resolved from ENV, variable
+// defaults and default tag
+func (l *Loader) applyValue(f *field) error {
+	value, elems, provided := l.resolveValue(f)
+	if !provided && !f.tagged {
+		// Fields without any tag are only touched if a source provides a value
+		return nil
+	}
+
+	if !provided && (f.value.Kind() == reflect.Ptr || l.isCustomType(f.value.Type())) {
+		// Pointers and custom types keep their zero value unless any source provides a value
+		f.value.Set(reflect.Zero(f.value.Type()))
+		return nil
+	}
+
+	if elems != nil {
+		v, err := l.buildSlice(elems, f.value.Type())
+		if err != nil {
+			return fmt.Errorf("setting field %s: %w", f.name(), err)
+		}
+		f.value.Set(v)
+		return nil
+	}
+
+	if err := l.setField(f, value); err != nil {
+		return fmt.Errorf("setting field %s: %w", f.name(), err)
+	}
+
+	return nil
+}
+
+// setField parses the value into the field honoring the field specific
+// settings like the slice delimiter
+func (l *Loader) setField(f *field, value string) error {
+	if l.isListType(f.value.Type()) {
+		v, err := l.parseSlice(value, f.value.Type(), sliceDelimiter(f.structField))
+		if err != nil {
+			return err
+		}
+		f.value.Set(v)
+		return nil
+	}
+
+	return l.setFieldValue(f.value, f.value.Type(), value)
+}
+
+// registerFlags registers a flag for every field having a flag name
+func (l *Loader) registerFlags(fields []*field, fs *pflag.FlagSet) error {
+	if err := checkFlagsAvailable(fields, fs); err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if f.flag == "" {
+			continue
+		}
+
+		flag := fs.VarPF(l.newFlagValue(f), f.flag, f.flagShort, l.buildDescription(f))
+		if flag.Value.Type() == "bool" {
+			// Allow to use the flag without value like native bool flags
+			flag.NoOptDefVal = "true"
+		}
+
+		if isEmptyValue(f.value) {
+			// Do not show zero values like "0s" or "[]" as default in the usage
+			flag.DefValue = ""
+		}
+	}
+
+	return nil
+}
+
+// resolveValue determines the value of a field from the env variable, the
+// configuration file, the variable defaults and the default tag (in this
+// order of precedence). Slices stored in the variable defaults using
+// indexed keys are returned as separate elements instead of a value. The
+// last return value reports whether any of these sources provided a value.
+func (l *Loader) resolveValue(f *field) (string, []string, bool) {
+	if f.env != "" {
+		if v, ok := l.lookupEnv(f.env); ok {
+			return v, nil, true
+		}
+	}
+
+	if v, ok := l.lookupConfigFile(f); ok {
+		return v, nil, true
+	}
+
+	if v, elems, ok := l.lookupVarDefault(f); ok {
+		return v, elems, true
+	}
+
+	v, ok := f.structField.Tag.Lookup("default")
+	return v, nil, ok
+}
+
+func (l *Loader) lookupVarDefault(f *field) (string, []string, bool) {
+	if f.varDefault == "" {
+		return "", nil, false
+	}
+
+	key := f.varDefault
+	if f.varDefaultFold {
+		key = l.foldVarDefaultKey(key)
+	}
+
+	if l.isListType(f.value.Type()) {
+		// Indexed keys are preferred as their elements may contain the delimiter
+		if elems := l.varDefaultSlice(key); elems != nil {
+			return "", elems, true
+		}
+	}
+
+	if v, ok := l.varDefault(key); ok {
+		return v, nil, true
+	}
+
+	if f.value.Kind() == reflect.Map {
+		// Maps can be filled from a nested structure of variable defaults
+		v, ok := l.varDefaultMap(key)
+		return v, nil, ok
+	}
+
+	return "", nil, false
+}
+
+// varDefault looks up a single variable default preferring the values read
+// from the file selected by the config file flag
+func (l *Loader) varDefault(key string) (string, bool) {
+	if v, ok := l.configFlagDefaults[key]; ok {
+		return v, true
+	}
+
+	v, ok := l.variableDefaults[key]
+	return v, ok
+}
+
+// varDefaultKeys returns the keys of all variable defaults including the
+// ones read from the file selected by the config file flag
+func (l *Loader) varDefaultKeys() []string {
+	keys := make([]string, 0, len(l.variableDefaults)+len(l.configFlagDefaults))
+	for k := range l.variableDefaults {
+		keys = append(keys, k)
+	}
+
+	for k := range l.configFlagDefaults {
+		if _, ok := l.variableDefaults[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+// foldVarDefaultKey returns the key as spelled in the variable defaults
+// when it is only present with a different case (for example "Database.Host"
+// for "database.host"). Keys of nested values ("database.host.0") are taken
+// into account for slices and maps. An exact match is preferred and the key
+// is returned unchanged if no match is found.
+func (l *Loader) foldVarDefaultKey(key string) string {
+	var match string
+
+	for _, k := range l.varDefaultKeys() {
+		if len(k) < len(key) || (len(k) > len(key) && k[len(key)] != '.') {
+			continue
+		}
+
+		candidate := k[:len(key)]
+		if candidate == key {
+			return key
+		}
+
+		if strings.EqualFold(candidate, key) && (match == "" || candidate < match) {
+			match = candidate
+		}
+	}
+
+	if match == "" {
+		return key
+	}
+	return match
+}
+
+func (*Loader) buildDescription(f *field) string {
+	desc := f.structField.Tag.Get("description")
+	if f.env != "" {
+		if desc != "" {
+			desc += fmt.Sprintf(" (ENV: %s)", f.env)
+		} else {
+			desc = fmt.Sprintf("(ENV: %s)", f.env)
+		}
+	}
+	return desc
+}
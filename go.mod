@@ -3,6 +3,7 @@ module github.com/henrix88/rixconfig
 go 1.24.6
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/go-playground/validator/v10 v10.30.1
 	github.com/goccy/go-yaml v1.19.2
 	github.com/spf13/pflag v1.0.10
@@ -50,3 +50,31 @@ func TestIntParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestIntFlagBases(t *testing.T) {
+	var cfg struct {
+		Mode   uint32 `flag:"mode" default:"0755"`
+		Hex    int    `flag:"hex"`
+		Neg    int8   `flag:"neg"`
+		Ptr    *uint  `flag:"ptr"`
+		EnvDec int    `env:"RCONFIG_TEST_INT_BASE" default:"1"`
+	}
+
+	t.Setenv("RCONFIG_TEST_INT_BASE", "010")
+
+	if err := parse(&cfg, []string{"--mode", "0755", "--hex=0x10", "--neg=-0b11", "--ptr", "0o17"}); err != nil {
+		t.Fatalf("Parsing options caused error: %s", err)
+	}
+
+	for _, test := range [][2]interface{}{
+		{cfg.Mode, uint32(0o755)},
+		{cfg.Hex, 16},
+		{cfg.Neg, int8(-3)},
+		{*cfg.Ptr, uint(15)},
+		{cfg.EnvDec, 10},
+	} {
+		if test[0] != test[1] {
+			t.Errorf("Expected value does not match: %#v != %#v", test[0], test[1])
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package rconfig
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderIndependence(t *testing.T) {
+	type testcfg struct {
+		Name string `vardefault:"name" default:"unset" flag:"name"`
+		Port int    `vardefault:"port" default:"1"`
+	}
+
+	for i := 0; i < 10; i++ {
+		t.Run(fmt.Sprintf("loader%d", i), func(t *testing.T) {
+			t.Parallel()
+
+			l := New()
+			l.SetVariableDefaults(map[string]string{
+				"name": fmt.Sprintf("name%d", i),
+				"port": fmt.Sprintf("%d", 1000+i),
+			})
+
+			var cfg testcfg
+			require.NoError(t, l.parse(&cfg, []string{"positional"}))
+
+			assert.Equal(t, fmt.Sprintf("name%d", i), cfg.Name)
+			assert.Equal(t, 1000+i, cfg.Port)
+			assert.Equal(t, []string{"positional"}, l.Args())
+		})
+	}
+}
+
+func TestLoaderTimeParserFormats(t *testing.T) {
+	var cfg struct {
+		Test time.Time `default:"2024|01|02"`
+	}
+
+	l := New()
+	assert.Error(t, l.parse(&cfg, []string{}), "custom format must not be known")
+
+	l.AddTimeParserFormats("2006|01|02")
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, 2024, cfg.Test.Year())
+
+	// The default loader must not have picked up the format
+	assert.Error(t, parse(&cfg, []string{}))
+}
+
+func TestLoaderArgsBeforeParse(t *testing.T) {
+	assert.Nil(t, New().Args())
+}
@@ -0,0 +1,80 @@
+package rconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// parseMap creates a map of the given type from a list of "key=value"
+// pairs separated by commas. Keys and values are parsed into the key and
+// element type of the map using setFieldValue.
+func (l *Loader) parseMap(value string, mapType reflect.Type) (reflect.Value, error) {
+	m := reflect.MakeMap(mapType)
+
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+	if value == "" {
+		return m, nil
+	}
+
+	for _, pair := range splitMapPairs(value) {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%q must be formatted as key=value", pair)
+		}
+
+		k := reflect.New(mapType.Key()).Elem()
+		if err := l.setFieldValue(k, mapType.Key(), strings.TrimSpace(key)); err != nil {
+			return reflect.Value{}, fmt.Errorf("parsing map key %q: %w", key, err)
+		}
+
+		v := reflect.New(mapType.Elem()).Elem()
+		if err := l.setFieldValue(v, mapType.Elem(), strings.TrimSpace(val)); err != nil {
+			return reflect.Value{}, fmt.Errorf("parsing map value for key %q: %w", key, err)
+		}
+
+		m.SetMapIndex(k, v)
+	}
+
+	return m, nil
+}
+
+// splitMapPairs splits a list of "key=value" pairs at the commas. Parts
+// not containing a "=" are joined to the previous pair, so values may
+// contain commas themselves.
+func splitMapPairs(value string) []string {
+	var pairs []string
+
+	for _, part := range strings.Split(value, ",") {
+		if len(pairs) > 0 && !strings.Contains(part, "=") {
+			pairs[len(pairs)-1] += "," + part
+			continue
+		}
+		pairs = append(pairs, part)
+	}
+
+	return pairs
+}
+
+// varDefaultMap collects all variable defaults below the given key (for
+// example "labels.team" and "labels.env" for "labels") into a list of
+// "key=value" pairs. The second return value reports whether any key was
+// found.
+func (l *Loader) varDefaultMap(name string) (string, bool) {
+	var pairs []string
+
+	for _, k := range l.varDefaultKeys() {
+		if sub, ok := strings.CutPrefix(k, name+"."); ok {
+			v, _ := l.varDefault(k)
+			pairs = append(pairs, sub+"="+v)
+		}
+	}
+
+	if len(pairs) == 0 {
+		return "", false
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, ","), true
+}
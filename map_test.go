@@ -0,0 +1,94 @@
+package rconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapParsing(t *testing.T) {
+	var cfg struct {
+		Labels    map[string]string        `default:"team=core,env=dev" flag:"labels,l"`
+		Limits    map[string]int           `default:"a=1, b=2" env:"RCONFIG_TEST_LIMITS"`
+		Timeouts  map[string]time.Duration `default:"read=1s" flag:"timeouts"`
+		Headers   map[string]string        `default:"accept=text/plain,text/html"`
+		Untouched map[string]string
+	}
+
+	t.Setenv("RCONFIG_TEST_LIMITS", "c=3")
+
+	l := New()
+	require.NoError(t, l.parse(&cfg, []string{"--labels=env=prod", "-l", "region=eu", "--timeouts", "write=2m"}))
+
+	assert.Equal(t, map[string]string{"env": "prod", "region": "eu"}, cfg.Labels)
+	assert.Equal(t, map[string]int{"c": 3}, cfg.Limits)
+	assert.Equal(t, map[string]time.Duration{"write": 2 * time.Minute}, cfg.Timeouts)
+	assert.Equal(t, map[string]string{"accept": "text/plain,text/html"}, cfg.Headers)
+	assert.Nil(t, cfg.Untouched)
+}
+
+func TestMapVarDefaults(t *testing.T) {
+	var cfg struct {
+		Labels map[string]string `vardefault:"labels" flag:"labels"`
+		Ports  map[string]uint16 `vardefault:"ports"`
+		Direct map[string]string `vardefault:"direct" default:"a=b"`
+	}
+
+	defaults, err := VarDefaultsFromYAML([]byte("---\nlabels:\n  team: core\n  env: dev\nports:\n  http: 80\n  https: 443\ndirect: c=d\n"))
+	require.NoError(t, err)
+
+	l := New()
+	l.SetVariableDefaults(defaults)
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, map[string]string{"team": "core", "env": "dev"}, cfg.Labels)
+	assert.Equal(t, map[string]uint16{"http": 80, "https": 443}, cfg.Ports)
+	assert.Equal(t, map[string]string{"c": "d"}, cfg.Direct)
+}
+
+func TestMapApplyEnvAndDefaults(t *testing.T) {
+	type testcfg struct {
+		Labels map[string]string `default:"team=core" env:"RCONFIG_TEST_LABELS" flag:"labels"`
+		Limits map[string]int    `default:"a=1" env:"RCONFIG_TEST_LIMITS"`
+	}
+
+	t.Setenv("RCONFIG_TEST_LABELS", "team=edge")
+	t.Setenv("RCONFIG_TEST_LIMITS", "b=2")
+
+	var cfg testcfg
+	l := New()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	assert.Equal(t, map[string]string{"team": "edge"}, cfg.Labels)
+	assert.Equal(t, map[string]int{"b": 2}, cfg.Limits)
+
+	cfg = testcfg{}
+	fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{"--labels", "team=cli"}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	assert.Equal(t, map[string]string{"team": "cli"}, cfg.Labels)
+}
+
+func TestMapErrors(t *testing.T) {
+	for name, cfg := range map[string]interface{}{
+		"missing separator": &struct {
+			A map[string]string `default:"foo"`
+		}{},
+		"invalid value": &struct {
+			A map[string]int `default:"a=b"`
+		}{},
+		"invalid key": &struct {
+			A map[int]string `default:"a=b"`
+		}{},
+	} {
+		assert.Error(t, New().parse(cfg, []string{}), name)
+	}
+}
@@ -0,0 +1,109 @@
+package rconfig
+
+import "strings"
+
+// NamingStrategy derives a name (for example an env variable) from the parts
+// of a field path: the names of the fields or the prefix tags of the
+// sub-structs leading to the field followed by the name of the field itself.
+type NamingStrategy interface {
+	Name(parts []string) string
+}
+
+// NamingFunc is an adapter to use an ordinary function as NamingStrategy
+type NamingFunc func(parts []string) string
+
+// Name calls f(parts)
+func (f NamingFunc) Name(parts []string) string { return f(parts) }
+
+var (
+	// ScreamingSnakeCase derives names like `DATABASE_MAX_CONNS`
+	ScreamingSnakeCase NamingStrategy = NamingFunc(func(parts []string) string {
+		return strings.ToUpper(joinWords(parts, "_"))
+	})
+
+	// KebabCase derives names like `database-max-conns`
+	KebabCase NamingStrategy = NamingFunc(func(parts []string) string {
+		return strings.ToLower(joinWords(parts, "-"))
+	})
+
+	// DotCase derives names like `database.max.conns`
+	DotCase NamingStrategy = NamingFunc(func(parts []string) string {
+		return strings.ToLower(joinWords(parts, "."))
+	})
+
+	// dotPath joins the parts unchanged as the keys from `yaml` and `json`
+	// tags must not be split into words
+	dotPath NamingStrategy = NamingFunc(func(parts []string) string {
+		return strings.Join(parts, ".")
+	})
+)
+
+// joinWords splits all parts into their words and joins them using the
+// separator
+func joinWords(parts []string, sep string) string {
+	var words []string
+	for _, p := range parts {
+		words = append(words, splitWords(p)...)
+	}
+	return strings.Join(words, sep)
+}
+
+// SetEnvNaming sets the NamingStrategy used to derive env variable names
+// when AutoEnv is enabled. Defaults to ScreamingSnakeCase, passing nil
+// restores the default.
+//
+// The strategy only applies to derived names: names given in `env` tags
+// within a sub-struct carrying a `prefix` tag are always prefixed using the
+// fixed format (`prefix:"replica"` and `env:"HOST"` read `REPLICA_HOST`).
+func SetEnvNaming(s NamingStrategy) {
+	defaultLoader.SetEnvNaming(s)
+}
+
+// SetEnvNaming sets the NamingStrategy used by AutoEnv for this Loader
+func (l *Loader) SetEnvNaming(s NamingStrategy) {
+	if s == nil {
+		s = ScreamingSnakeCase
+	}
+	l.envNaming = s
+}
+
+// SetFlagNaming sets the NamingStrategy used to derive flag names when
+// AutoFlag is enabled. Defaults to KebabCase, passing nil restores the
+// default.
+//
+// The strategy only applies to derived names: names given in `flag` tags
+// within a sub-struct carrying a `prefix` tag are always prefixed using the
+// fixed format (`prefix:"replica"` and `flag:"host"` read `--replica-host`).
+func SetFlagNaming(s NamingStrategy) {
+	defaultLoader.SetFlagNaming(s)
+}
+
+// SetFlagNaming sets the NamingStrategy used by AutoFlag for this Loader
+func (l *Loader) SetFlagNaming(s NamingStrategy) {
+	if s == nil {
+		s = KebabCase
+	}
+	l.flagNaming = s
+}
+
+// SetVarDefaultNaming sets the NamingStrategy used to derive vardefault keys
+// when AutoVarDefault is enabled. The parts passed to the strategy are the
+// keys from the `yaml` or `json` tags or the field names. By default the
+// parts are joined using dots without further changes, passing nil restores
+// the default.
+//
+// Keys given in `vardefault` tags within a sub-struct carrying a `prefix`
+// tag are always prefixed using the fixed format (`prefix:"replica"` and
+// `vardefault:"host"` read `replica.host`).
+func SetVarDefaultNaming(s NamingStrategy) {
+	defaultLoader.SetVarDefaultNaming(s)
+}
+
+// SetVarDefaultNaming sets the NamingStrategy used by AutoVarDefault for
+// this Loader
+func (l *Loader) SetVarDefaultNaming(s NamingStrategy) {
+	if s == nil {
+		s = dotPath
+	}
+	l.varDefaultNaming = s
+}
@@ -0,0 +1,61 @@
+package rconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	parts := []string{"Database", "MaxConns"}
+
+	assert.Equal(t, "DATABASE_MAX_CONNS", ScreamingSnakeCase.Name(parts))
+	assert.Equal(t, "database-max-conns", KebabCase.Name(parts))
+	assert.Equal(t, "database.max.conns", DotCase.Name(parts))
+
+	assert.Equal(t, "replica-http-addr", KebabCase.Name([]string{"replica", "HTTPAddr"}))
+	assert.Equal(t, "", KebabCase.Name(nil))
+
+	custom := NamingFunc(func(parts []string) string { return strings.Join(parts, "__") })
+	assert.Equal(t, "Database__MaxConns", custom.Name(parts))
+}
+
+func TestSetEnvNaming(t *testing.T) {
+	type testcfg struct {
+		Port     int
+		Database struct {
+			IPv4Addr string
+		}
+		Replica struct {
+			User string
+		} `prefix:"replica"`
+	}
+
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_DATABASE_IPV4ADDR", "127.0.0.1")
+	t.Setenv("APP_REPLICA_USER", "replicator")
+
+	l := New()
+	l.AutoEnv(true)
+	l.AutoEnvNested(true)
+	l.SetEnvNaming(NamingFunc(func(parts []string) string {
+		return "APP_" + strings.ToUpper(strings.Join(parts, "_"))
+	}))
+
+	var cfg testcfg
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, "127.0.0.1", cfg.Database.IPv4Addr)
+	assert.Equal(t, "replicator", cfg.Replica.User)
+
+	t.Setenv("REPLICA_USER", "default")
+	l.AutoEnvNested(false)
+	l.SetEnvNaming(nil)
+
+	cfg = testcfg{}
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, "default", cfg.Replica.User)
+}
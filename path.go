@@ -0,0 +1,139 @@
+package rconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envReferencePattern matches $NAME and ${NAME} references within a path
+var envReferencePattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// ExpandPath expands a leading "~" to the home directory of the user and
+// environment variables like $HOME or ${XDG_CONFIG_HOME} within the path.
+// If XDG_CONFIG_HOME is not set it defaults to ~/.config. References to
+// other variables not being set are kept as they are, so a literal "$"
+// in a filename is preserved.
+func ExpandPath(path string) (string, error) {
+	var home string
+	lookupHome := func() (string, error) {
+		if home != "" {
+			return home, nil
+		}
+
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("getting home directory: %w", err)
+		}
+		home = h
+		return home, nil
+	}
+
+	var expandErr error
+	path = envReferencePattern.ReplaceAllStringFunc(path, func(ref string) string {
+		m := envReferencePattern.FindStringSubmatch(ref)
+		name := m[1] + m[2]
+
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			return v
+		}
+
+		switch name {
+		case "HOME":
+			h, err := lookupHome()
+			if err != nil {
+				expandErr = err
+			}
+			return h
+
+		case "XDG_CONFIG_HOME":
+			h, err := lookupHome()
+			if err != nil {
+				expandErr = err
+			}
+			return filepath.Join(h, ".config")
+		}
+
+		return ref
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		h, err := lookupHome()
+		if err != nil {
+			return "", err
+		}
+		path = h + path[1:]
+	}
+
+	return path, nil
+}
+
+// ConfigFileCandidates returns the default locations to search for the
+// configuration files of an application in order of preference:
+// ${XDG_CONFIG_HOME}/<app>, the directories in ${XDG_CONFIG_DIRS} (defaults
+// to /etc/xdg) below <app>, /etc/<app> and the current working directory.
+// The result is meant to be passed to FindFile.
+func ConfigFileCandidates(app string, names ...string) []string {
+	dirs := []string{filepath.Join("${XDG_CONFIG_HOME}", app)}
+
+	xdgDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if xdgDirs == "" {
+		xdgDirs = "/etc/xdg"
+	}
+	for _, dir := range filepath.SplitList(xdgDirs) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, app))
+		}
+	}
+
+	dirs = append(dirs, filepath.Join("/etc", app), ".")
+
+	candidates := make([]string, 0, len(dirs)*len(names))
+	for _, dir := range dirs {
+		for _, name := range names {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+	}
+
+	return candidates
+}
+
+// FindFile expands the candidates using ExpandPath and returns the first
+// one being an existing file. Candidates which cannot be expanded (for
+// example because $HOME is not set) are skipped. If none exists the
+// returned error matches fs.ErrNotExist and contains the expansion errors.
+func FindFile(candidates ...string) (string, error) {
+	var expandErrs []error
+
+	for _, candidate := range candidates {
+		path, err := ExpandPath(candidate)
+		if err != nil {
+			expandErrs = append(expandErrs, err)
+			continue
+		}
+
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+
+	notFound := fmt.Errorf("none of %s found: %w", strings.Join(candidates, ", "), fs.ErrNotExist)
+	return "", errors.Join(append([]error{notFound}, expandErrs...)...)
+}
+
+// readFile expands the filename using ExpandPath and reads its contents
+func readFile(filename string) ([]byte, error) {
+	path, err := ExpandPath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path) //#nosec:G304 // Loading file from var is intended
+}
@@ -0,0 +1,125 @@
+package rconfig
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("RCONFIG_TEST_DIR", "/srv")
+	t.Setenv("b", "")
+	require.NoError(t, os.Unsetenv("b"))
+	require.NoError(t, os.Unsetenv("RCONFIG_TEST_UNSET"))
+
+	for in, expect := range map[string]string{
+		"~":                           "/home/test",
+		"~/.myapp.yml":                "/home/test/.myapp.yml",
+		"$HOME/app.yml":               "/home/test/app.yml",
+		"${XDG_CONFIG_HOME}/app.yml":  "/home/test/.config/app.yml",
+		"$RCONFIG_TEST_DIR/app.yml":   "/srv/app.yml",
+		"/etc/app.yml":                "/etc/app.yml",
+		"./~app.yml":                  "./~app.yml",
+		"~other/app.yml":              "~other/app.yml",
+		"${RCONFIG_TEST_UNSET}/a.yml": "${RCONFIG_TEST_UNSET}/a.yml",
+		"$RCONFIG_TEST_UNSET/a.yml":   "$RCONFIG_TEST_UNSET/a.yml",
+		"/tmp/a$b.yml":                "/tmp/a$b.yml",
+		"/tmp/price$.yml":             "/tmp/price$.yml",
+		"/tmp/a${.yml":                "/tmp/a${.yml",
+	} {
+		path, err := ExpandPath(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, expect, path, in)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	path, err := ExpandPath("${XDG_CONFIG_HOME}/app.yml")
+	require.NoError(t, err)
+	assert.Equal(t, "/xdg/app.yml", path)
+}
+
+func TestConfigFileCandidates(t *testing.T) {
+	t.Setenv("XDG_CONFIG_DIRS", "")
+	assert.Equal(t, []string{
+		"${XDG_CONFIG_HOME}/myapp/config.yml",
+		"/etc/xdg/myapp/config.yml",
+		"/etc/myapp/config.yml",
+		"config.yml",
+	}, ConfigFileCandidates("myapp", "config.yml"))
+
+	t.Setenv("XDG_CONFIG_DIRS", "/a:/b")
+	assert.Equal(t, []string{
+		"${XDG_CONFIG_HOME}/myapp/config.yml",
+		"${XDG_CONFIG_HOME}/myapp/config.json",
+		"/a/myapp/config.yml",
+		"/a/myapp/config.json",
+		"/b/myapp/config.yml",
+		"/b/myapp/config.json",
+		"/etc/myapp/config.yml",
+		"/etc/myapp/config.json",
+		"config.yml",
+		"config.json",
+	}, ConfigFileCandidates("myapp", "config.yml", "config.json"))
+}
+
+func TestFindFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".config", "myapp", "dir.yml"), 0o700))
+	expected := filepath.Join(home, ".config", "myapp", "config.yml")
+	require.NoError(t, os.WriteFile(expected, []byte("user: luzifer\n"), 0o600))
+
+	path, err := FindFile(
+		"~/missing.yml",
+		"${XDG_CONFIG_HOME}/myapp/dir.yml",
+		"${XDG_CONFIG_HOME}/myapp/config.yml",
+		"~/.myapp.yml",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, expected, path)
+
+	_, err = FindFile("~/missing.yml")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	// Candidates which cannot be expanded are skipped
+	t.Setenv("HOME", "")
+	path, err = FindFile("~/.myapp.yml", "${XDG_CONFIG_HOME}/myapp/config.yml", expected)
+	require.NoError(t, err)
+	assert.Equal(t, expected, path)
+
+	_, err = FindFile("~/.myapp.yml")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorContains(t, err, "HOME")
+}
+
+func TestVarDefaultsFromFileExpandsPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".myapp.yml"), []byte("username: luzifer\n"), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(home, "secrets"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, "secrets", "password"), []byte("secret\n"), 0o600))
+
+	defaults, err := VarDefaultsFromYAMLFile("~/.myapp.yml")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "luzifer"}, defaults)
+
+	defaults, err = VarDefaultsFromDir("$HOME/secrets")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"password": "secret"}, defaults)
+
+	var cfg struct {
+		Username string `yaml:"username"`
+	}
+	l := New()
+	require.NoError(t, l.SetConfigFile("~/.myapp.yml"))
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, "luzifer", cfg.Username)
+}
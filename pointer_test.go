@@ -0,0 +1,81 @@
+package rconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerParsing(t *testing.T) {
+	type testcfg struct {
+		Int      *int           `flag:"int"`
+		ZeroInt  *int           `default:"0"`
+		String   *string        `env:"RCONFIG_TEST_POINTER_STRING"`
+		Bool     *bool          `flag:"bool,b"`
+		Timeout  *time.Duration `vardefault:"timeout"`
+		Time     *time.Time     `default:"2024-01-02T15:04:05Z"`
+		Untagged *int
+	}
+
+	var cfg testcfg
+
+	l := New()
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Nil(t, cfg.Int)
+	require.NotNil(t, cfg.ZeroInt)
+	assert.Equal(t, 0, *cfg.ZeroInt)
+	assert.Nil(t, cfg.String)
+	assert.Nil(t, cfg.Bool)
+	assert.Nil(t, cfg.Timeout)
+	require.NotNil(t, cfg.Time)
+	assert.Equal(t, 2024, cfg.Time.Year())
+	assert.Nil(t, cfg.Untagged)
+
+	t.Setenv("RCONFIG_TEST_POINTER_STRING", "")
+	l.SetVariableDefaults(map[string]string{"timeout": "5s"})
+
+	cfg = testcfg{}
+	require.NoError(t, l.parse(&cfg, []string{"--int=0", "-b"}))
+
+	require.NotNil(t, cfg.Int)
+	assert.Equal(t, 0, *cfg.Int)
+	require.NotNil(t, cfg.String)
+	assert.Equal(t, "", *cfg.String)
+	require.NotNil(t, cfg.Bool)
+	assert.True(t, *cfg.Bool)
+	require.NotNil(t, cfg.Timeout)
+	assert.Equal(t, 5*time.Second, *cfg.Timeout)
+}
+
+func TestPointerApplyEnvAndDefaults(t *testing.T) {
+	type testcfg struct {
+		Override *time.Duration `env:"RCONFIG_TEST_POINTER_OVERRIDE" flag:"override"`
+		Retries  *int           `env:"RCONFIG_TEST_POINTER_RETRIES" flag:"retries"`
+		Name     *string        `env:"RCONFIG_TEST_POINTER_NAME"`
+	}
+
+	t.Setenv("RCONFIG_TEST_POINTER_RETRIES", "3")
+
+	var cfg testcfg
+	l := New()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{"--override=1m"}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	require.NotNil(t, cfg.Override)
+	assert.Equal(t, time.Minute, *cfg.Override)
+	require.NotNil(t, cfg.Retries)
+	assert.Equal(t, 3, *cfg.Retries)
+	assert.Nil(t, cfg.Name)
+}
+
+func TestPointerErrors(t *testing.T) {
+	assert.Error(t, New().parse(&struct {
+		A *int `default:"a"`
+	}{}, []string{}))
+}
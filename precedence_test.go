@@ -3,7 +3,9 @@ package rconfig
 import (
 	"os"
 	"testing"
+	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -61,3 +63,60 @@ func TestPrecedence(t *testing.T) {
 
 	exec("Provided: Default", func() interface{} { return cfg.A }, 1)
 }
+
+func TestPrecedenceEntryPoints(t *testing.T) {
+	type testcfg struct {
+		Int      int               `default:"1" vardefault:"int" env:"RCONFIG_TEST_INT" flag:"int"`
+		Bool     bool              `vardefault:"bool" env:"RCONFIG_TEST_BOOL" flag:"bool"`
+		Duration time.Duration     `default:"1s" env:"RCONFIG_TEST_DURATION"`
+		Time     time.Time         `vardefault:"time"`
+		Strings  []string          `default:"a" env:"RCONFIG_TEST_STRINGS" flag:"strings"`
+		Labels   map[string]string `vardefault:"labels" flag:"labels"`
+		Optional *uint             `env:"RCONFIG_TEST_OPTIONAL"`
+		Untagged int
+	}
+
+	t.Setenv("RCONFIG_TEST_INT", "8")
+	t.Setenv("RCONFIG_TEST_BOOL", "1")
+	t.Setenv("RCONFIG_TEST_STRINGS", "b,c")
+
+	vardefaults := map[string]string{
+		"int":       "3",
+		"bool":      "false",
+		"time":      "1700000000",
+		"labels.a":  "b",
+		"unrelated": "x",
+	}
+
+	args := []string{"--int=5", "--labels", "c=d"}
+
+	viaParse := testcfg{Untagged: 42}
+	l := New()
+	l.SetVariableDefaults(vardefaults)
+	require.NoError(t, l.parse(&viaParse, args))
+
+	viaApply := testcfg{Untagged: 42}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&viaApply, fs))
+	require.NoError(t, fs.Parse(args))
+	require.NoError(t, l.ApplyEnvAndDefaults(&viaApply, fs))
+
+	for name, cfg := range map[string]testcfg{"Parse": viaParse, "ApplyEnvAndDefaults": viaApply} {
+		assert.Equal(t, 5, cfg.Int, name)
+		assert.True(t, cfg.Bool, name)
+		assert.Equal(t, time.Second, cfg.Duration, name)
+		assert.Equal(t, time.Unix(1700000000, 0), cfg.Time, name)
+		assert.Equal(t, []string{"b", "c"}, cfg.Strings, name)
+		assert.Equal(t, map[string]string{"c": "d"}, cfg.Labels, name)
+		assert.Nil(t, cfg.Optional, name)
+		assert.Equal(t, 42, cfg.Untagged, name)
+	}
+}
+
+func TestPrecedenceInvalidBool(t *testing.T) {
+	t.Setenv("RCONFIG_TEST_BOOL", "yes please")
+
+	assert.Error(t, New().parse(&struct {
+		Bool bool `env:"RCONFIG_TEST_BOOL"`
+	}{}, []string{}))
+}
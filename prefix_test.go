@@ -0,0 +1,89 @@
+package rconfig
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDBConfig struct {
+	Host string `default:"localhost" env:"DB_HOST" flag:"db-host,d" vardefault:"db.host"`
+	Port int    `default:"5432" flag:"db-port"`
+	User string `vardefault:"db.user"`
+	Name string `default:"app"`
+}
+
+func TestPrefixParsing(t *testing.T) {
+	var cfg struct {
+		Primary testDBConfig
+		Replica testDBConfig `prefix:"replica"`
+		Nested  struct {
+			Cache testDBConfig `prefix:"cache"`
+		} `prefix:"nested"`
+	}
+
+	t.Setenv("DB_HOST", "primary.example.com")
+	t.Setenv("REPLICA_DB_HOST", "replica.example.com")
+	t.Setenv("REPLICA_NAME", "ignored-without-autoenv")
+
+	l := New()
+	l.SetVariableDefaults(map[string]string{
+		"db.user":              "primary-user",
+		"replica.db.user":      "replica-user",
+		"nested.cache.db.host": "cache.example.com",
+	})
+	require.NoError(t, l.parse(&cfg, []string{"-d", "cli.example.com", "--replica-db-port=5433", "--nested-cache-db-port", "6379"}))
+
+	assert.Equal(t, "cli.example.com", cfg.Primary.Host)
+	assert.Equal(t, 5432, cfg.Primary.Port)
+	assert.Equal(t, "primary-user", cfg.Primary.User)
+
+	assert.Equal(t, "replica.example.com", cfg.Replica.Host)
+	assert.Equal(t, 5433, cfg.Replica.Port)
+	assert.Equal(t, "replica-user", cfg.Replica.User)
+	assert.Equal(t, "app", cfg.Replica.Name)
+
+	assert.Equal(t, "cache.example.com", cfg.Nested.Cache.Host)
+	assert.Equal(t, 6379, cfg.Nested.Cache.Port)
+
+	assert.Equal(t, "db-host", l.fs.ShorthandLookup("d").Name)
+}
+
+func TestPrefixAutoEnv(t *testing.T) {
+	var cfg struct {
+		Primary testDBConfig
+		Replica testDBConfig `prefix:"read-replica"`
+	}
+
+	t.Setenv("READ_REPLICA_NAME", "replica")
+
+	l := New()
+	l.AutoEnv(true)
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, "app", cfg.Primary.Name)
+	assert.Equal(t, "replica", cfg.Replica.Name)
+	assert.Contains(t, l.fs.Lookup("read-replica-db-host").Usage, "(ENV: READ_REPLICA_DB_HOST)")
+}
+
+func TestPrefixApplyEnvAndDefaults(t *testing.T) {
+	var cfg struct {
+		Primary testDBConfig
+		Replica testDBConfig `prefix:"replica"`
+	}
+
+	t.Setenv("REPLICA_DB_HOST", "replica.example.com")
+
+	l := New()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{"--db-port=1"}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	assert.Equal(t, "localhost", cfg.Primary.Host)
+	assert.Equal(t, 1, cfg.Primary.Port)
+	assert.Equal(t, "replica.example.com", cfg.Replica.Host)
+	assert.Equal(t, 5432, cfg.Replica.Port)
+}
@@ -0,0 +1,76 @@
+package rconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseSlice creates a slice of the given type from a list of values
+// separated by the delimiter. Each element is parsed into the element type
+// of the slice using setFieldValue. An empty input yields an empty slice.
+func (l *Loader) parseSlice(value string, sliceType reflect.Type, delimiter string) (reflect.Value, error) {
+	if strings.TrimSpace(value) == "" {
+		return reflect.MakeSlice(sliceType, 0, 0), nil
+	}
+
+	return l.buildSlice(strings.Split(value, delimiter), sliceType)
+}
+
+// buildSlice creates a slice of the given type from the already separated
+// elements parsing each of them using setFieldValue
+func (l *Loader) buildSlice(elems []string, sliceType reflect.Type) (reflect.Value, error) {
+	s := reflect.MakeSlice(sliceType, 0, len(elems))
+
+	for _, part := range elems {
+		if sliceType.Elem().Kind() != reflect.String {
+			part = strings.TrimSpace(part)
+		}
+
+		e := reflect.New(sliceType.Elem()).Elem()
+		if err := l.setFieldValue(e, sliceType.Elem(), part); err != nil {
+			return reflect.Value{}, fmt.Errorf("parsing slice element %q: %w", part, err)
+		}
+		s = reflect.Append(s, e)
+	}
+
+	return s, nil
+}
+
+// sliceDelimiter returns the delimiter to split slice values at as
+// configured in the delimiter tag (defaults to a comma)
+func sliceDelimiter(field reflect.StructField) string {
+	if del := field.Tag.Get("delimiter"); del != "" {
+		return del
+	}
+	return ","
+}
+
+// varDefaultSlice collects the variable defaults stored using indexed keys
+// (for example "hosts.0" and "hosts.1" for "hosts") as separate elements,
+// so elements may contain the delimiter. All elements are taken from the
+// layer with the highest precedence setting the list so elements of
+// different layers are never mixed. Returns nil if that layer does not
+// contain indexed keys.
+func (l *Loader) varDefaultSlice(name string) []string {
+	for _, layer := range []map[string]string{l.configFlagDefaults, l.variableDefaults} {
+		_, hasValue := layer[name]
+		_, hasIndex := layer[name+".0"]
+		if !hasValue && !hasIndex {
+			continue
+		}
+
+		var elems []string
+		for i := 0; hasIndex; i++ {
+			v, ok := layer[name+"."+strconv.Itoa(i)]
+			if !ok {
+				break
+			}
+			elems = append(elems, v)
+		}
+		return elems
+	}
+
+	return nil
+}
@@ -3,6 +3,11 @@ package rconfig
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSliceParsing(t *testing.T) {
@@ -43,3 +48,133 @@ func TestSliceParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestSliceElementTypes(t *testing.T) {
+	var cfg struct {
+		Uint      []uint          `default:"1,2" flag:"uint"`
+		Int8      []int8          `default:"-1, 2"`
+		Int64     []int64         `default:"" flag:"int64"`
+		Float64   []float64       `default:"1.5;2.5" delimiter:";"`
+		Bool      []bool          `default:"true,false" flag:"bool"`
+		Duration  []time.Duration `default:"1s,1m" flag:"duration,d"`
+		Time      []time.Time     `default:"2024-01-02T15:04:05Z|18.09.2018 20:25:31" delimiter:"|"`
+		String    []string        `default:"a|b" delimiter:"|" flag:"string"`
+		EmptyInts []int           `flag:"empty-ints"`
+	}
+
+	require.NoError(t, New().parse(&cfg, []string{
+		"--int64=5,6",
+		"-d", "2h", "-d", "3h",
+		"--string=c|d",
+	}))
+
+	assert.Equal(t, []uint{1, 2}, cfg.Uint)
+	assert.Equal(t, []int8{-1, 2}, cfg.Int8)
+	assert.Equal(t, []int64{5, 6}, cfg.Int64)
+	assert.Equal(t, []float64{1.5, 2.5}, cfg.Float64)
+	assert.Equal(t, []bool{true, false}, cfg.Bool)
+	assert.Equal(t, []time.Duration{2 * time.Hour, 3 * time.Hour}, cfg.Duration)
+	require.Len(t, cfg.Time, 2)
+	assert.Equal(t, 2024, cfg.Time[0].Year())
+	assert.Equal(t, 2018, cfg.Time[1].Year())
+	assert.Equal(t, []string{"c", "d"}, cfg.String)
+	assert.Equal(t, []int{}, cfg.EmptyInts)
+}
+
+func TestSliceElementErrors(t *testing.T) {
+	for name, cfg := range map[string]interface{}{
+		"invalid uint": &struct {
+			A []uint `default:"-1"`
+		}{},
+		"invalid float": &struct {
+			A []float64 `default:"a"`
+		}{},
+		"invalid duration": &struct {
+			A []time.Duration `default:"1x"`
+		}{},
+		"invalid time": &struct {
+			A []time.Time `default:"yesterday"`
+		}{},
+	} {
+		assert.Error(t, New().parse(cfg, []string{}), name)
+	}
+}
+
+func TestSliceWithoutFlag(t *testing.T) {
+	var cfg struct {
+		Peers   []string `env:"RCONFIG_TEST_PEERS"`
+		Ports   []int    `vardefault:"ports"`
+		Weights []uint   `default:"1;2" delimiter:";"`
+	}
+
+	t.Setenv("RCONFIG_TEST_PEERS", "a:1,b:2")
+
+	l := New()
+	l.SetVariableDefaults(map[string]string{"ports": "80,443"})
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, []string{"a:1", "b:2"}, cfg.Peers)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Equal(t, []uint{1, 2}, cfg.Weights)
+	assert.Nil(t, l.fs.Lookup(""), "no flag must be registered for flag-less slices")
+}
+
+func TestSliceApplyEnvAndDefaults(t *testing.T) {
+	type testcfg struct {
+		Peers   []string        `env:"RCONFIG_TEST_PEERS" delimiter:" "`
+		Ports   []int           `default:"80" env:"RCONFIG_TEST_PORTS" flag:"ports"`
+		Hosts   []string        `default:"a,b" env:"RCONFIG_TEST_HOSTS" flag:"hosts"`
+		Timeout []time.Duration `vardefault:"timeouts" flag:"timeouts"`
+		Empty   []int           `env:"RCONFIG_TEST_UNSET_SLICE"`
+	}
+
+	t.Setenv("RCONFIG_TEST_PEERS", "a:1 b:2")
+	t.Setenv("RCONFIG_TEST_PORTS", "8080,8443")
+	t.Setenv("RCONFIG_TEST_HOSTS", "c,d")
+
+	var cfg testcfg
+	l := New()
+	l.SetVariableDefaults(map[string]string{"timeouts": "1s,2s"})
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, l.RegisterFlags(&cfg, fs))
+	require.NoError(t, fs.Parse([]string{"--hosts=e", "--hosts=f"}))
+	require.NoError(t, l.ApplyEnvAndDefaults(&cfg, fs))
+
+	assert.Equal(t, []string{"a:1", "b:2"}, cfg.Peers)
+	assert.Equal(t, []int{8080, 8443}, cfg.Ports)
+	assert.Equal(t, []string{"e", "f"}, cfg.Hosts)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, cfg.Timeout)
+	assert.Equal(t, []int{}, cfg.Empty)
+}
+
+func TestByteSlice(t *testing.T) {
+	type testcfg struct {
+		Key     []byte `env:"RCONFIG_TEST_BYTES_KEY"`
+		Default []byte `default:"a,b" flag:"default"`
+		Flag    []byte `flag:"flag"`
+	}
+
+	t.Setenv("RCONFIG_TEST_BYTES_KEY", "s3cret")
+
+	var cfg testcfg
+	require.NoError(t, New().parse(&cfg, []string{"--flag", "1,2"}))
+	assert.Equal(t, []byte("s3cret"), cfg.Key)
+	assert.Equal(t, []byte("a,b"), cfg.Default)
+	assert.Equal(t, []byte("1,2"), cfg.Flag)
+}
+
+func TestStringSliceFlagCSV(t *testing.T) {
+	var cfg struct {
+		Hosts []string `flag:"hosts"`
+		Paths []string `flag:"paths" delimiter:";"`
+	}
+
+	require.NoError(t, New().parse(&cfg, []string{`--hosts="a,b",c`, "--hosts", "d", `--paths="a;b`}))
+	assert.Equal(t, []string{"a,b", "c", "d"}, cfg.Hosts)
+	assert.Equal(t, []string{`"a`, "b"}, cfg.Paths, "custom delimiters are not read as CSV")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	require.NoError(t, New().RegisterFlags(&cfg, fs))
+	assert.Error(t, fs.Parse([]string{`--hosts="a`}), "unterminated quotes must be reported")
+}
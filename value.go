@@ -0,0 +1,320 @@
+package rconfig
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	pflagValueType      = reflect.TypeOf((*pflag.Value)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// fieldValue implements the pflag.Value interface on top of setFieldValue
+// to expose fields as flags whose types have no native pflag support
+type fieldValue struct {
+	changed   bool
+	delimiter string
+	field     reflect.Value
+	loader    *Loader
+}
+
+// newFlagValue returns the pflag.Value to register for the field: types
+// implementing pflag.Value themselves are used directly unless a decoder
+// was registered for them, all other types are wrapped into a fieldValue
+func (l *Loader) newFlagValue(f *field) pflag.Value {
+	if _, ok := l.decoders[f.value.Type()]; !ok {
+		if v, ok := f.value.Addr().Interface().(pflag.Value); ok {
+			return v
+		}
+	}
+
+	return &fieldValue{
+		delimiter: sliceDelimiter(f.structField),
+		field:     f.value,
+		loader:    l,
+	}
+}
+
+func (f *fieldValue) Set(s string) error {
+	if f.loader.isListType(f.field.Type()) {
+		v, err := f.parseSlice(s)
+		if err != nil {
+			return err
+		}
+		if f.changed {
+			// Repeated flags extend the slice instead of replacing it
+			v = reflect.AppendSlice(f.field, v)
+		}
+		f.field.Set(v)
+		f.changed = true
+		return nil
+	}
+
+	if f.field.Kind() == reflect.Map && f.changed {
+		// Repeated flags extend the map instead of replacing it
+		m, err := f.loader.parseMap(s, f.field.Type())
+		if err != nil {
+			return err
+		}
+		iter := m.MapRange()
+		for iter.Next() {
+			f.field.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return nil
+	}
+
+	if ok, err := f.setInteger(s); ok || err != nil {
+		f.changed = err == nil
+		return err
+	}
+
+	if err := f.loader.setFieldValue(f.field, f.field.Type(), s); err != nil {
+		return err
+	}
+	f.changed = true
+	return nil
+}
+
+// setInteger parses values of integer flags (and pointers to them) with
+// base 0 like the native pflag integer flags, so "0755" and "0x10" are
+// accepted. Returns false for all other types.
+func (f *fieldValue) setInteger(s string) (bool, error) {
+	t := f.field.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if s == "" || f.loader.isCustomType(f.field.Type()) || f.loader.isCustomType(t) || t == reflect.TypeOf(time.Duration(0)) {
+		return false, nil
+	}
+
+	v := reflect.New(t)
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := parseIntForType(s, 0, t.Kind())
+		if err != nil {
+			return true, err
+		}
+		v.Elem().SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := parseUintForType(s, 0, t.Kind())
+		if err != nil {
+			return true, err
+		}
+		v.Elem().SetUint(i)
+
+	default:
+		return false, nil
+	}
+
+	if f.field.Kind() == reflect.Ptr {
+		f.field.Set(v)
+	} else {
+		f.field.Set(v.Elem())
+	}
+	return true, nil
+}
+
+// parseSlice parses a slice flag value. String elements separated by a
+// comma are read as CSV like pflag does, so elements may contain commas
+// when quoted ("a,b",c).
+func (f *fieldValue) parseSlice(s string) (reflect.Value, error) {
+	if f.delimiter != "," || f.field.Type().Elem().Kind() != reflect.String || strings.TrimSpace(s) == "" {
+		return f.loader.parseSlice(s, f.field.Type(), f.delimiter)
+	}
+
+	elems, err := csv.NewReader(strings.NewReader(s)).Read()
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("reading slice elements: %w", err)
+	}
+	return f.loader.buildSlice(elems, f.field.Type())
+}
+
+func (f *fieldValue) String() string {
+	if !f.field.IsValid() {
+		return ""
+	}
+	return formatValue(f.field)
+}
+
+func (f *fieldValue) Type() string {
+	return f.loader.typeName(f.field.Type())
+}
+
+// isCustomType reports whether the type is not parsed by the built-in
+// logic: either a decoder was registered for it or it brings its own
+// parsing logic by implementing pflag.Value or encoding.TextUnmarshaler.
+// time.Time is excluded as it is parsed using the time parser formats of
+// the Loader.
+func (l *Loader) isCustomType(t reflect.Type) bool {
+	if _, ok := l.decoders[t]; ok {
+		return true
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+
+	pt := reflect.PointerTo(t)
+	return pt.Implements(pflagValueType) || pt.Implements(textUnmarshalerType)
+}
+
+// isListType reports whether the type is a slice whose elements are parsed
+// separately. Byte slices are assigned the raw value instead.
+func (l *Loader) isListType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && !l.isCustomType(t) && !isByteSlice(t)
+}
+
+// isByteSlice reports whether the type is a slice of bytes
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && t.Elem().PkgPath() == ""
+}
+
+// isSupportedType reports whether values of the type can be parsed by
+// setFieldValue
+func (l *Loader) isSupportedType(t reflect.Type) bool {
+	if l.isCustomType(t) || t == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+
+	case reflect.Ptr, reflect.Slice:
+		return l.isSupportedType(t.Elem())
+
+	case reflect.Map:
+		return l.isSupportedType(t.Key()) && l.isSupportedType(t.Elem())
+
+	default:
+		return false
+	}
+}
+
+// setCustomValue sets the value of a field whose type is a custom type
+// as reported by isCustomType
+func (l *Loader) setCustomValue(field reflect.Value, value string) error {
+	if dec, ok := l.decoders[field.Type()]; ok {
+		return l.decode(field, dec, value)
+	}
+
+	switch v := field.Addr().Interface().(type) {
+	case pflag.Value:
+		return v.Set(value) //nolint:wrapcheck
+
+	case encoding.TextUnmarshaler:
+		return v.UnmarshalText([]byte(value)) //nolint:wrapcheck
+
+	default:
+		return fmt.Errorf("unsupported custom type %s", field.Type())
+	}
+}
+
+// isEmptyValue reports whether the value is the zero value of its type or
+// an empty slice or map
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// formatValue renders a value in the format understood by setFieldValue
+func formatValue(v reflect.Value) string {
+	switch v.Type() {
+	case reflect.TypeOf(time.Duration(0)):
+		return v.Interface().(time.Duration).String()
+
+	case reflect.TypeOf(time.Time{}):
+		if t := v.Interface().(time.Time); !t.IsZero() {
+			return t.Format(time.RFC3339Nano)
+		}
+		return ""
+	}
+
+	if isByteSlice(v.Type()) {
+		return string(v.Bytes())
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		return formatValue(v.Elem())
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		v = v.Addr()
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		elems := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elems = append(elems, formatValue(v.Index(i)))
+		}
+		return "[" + strings.Join(elems, ",") + "]"
+
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, formatValue(iter.Key())+"="+formatValue(iter.Value()))
+		}
+		sort.Strings(pairs)
+		return "[" + strings.Join(pairs, ",") + "]"
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// typeName returns the name of the type used in the usage output following
+// the naming scheme of pflag
+func (l *Loader) typeName(t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case reflect.TypeOf(time.Time{}):
+		return "time"
+	}
+
+	if l.isCustomType(t) && t.Name() != "" {
+		return strings.ToLower(t.Name()[:1]) + t.Name()[1:]
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return l.typeName(t.Elem())
+
+	case reflect.Slice:
+		return l.typeName(t.Elem()) + "Slice"
+
+	case reflect.Map:
+		elem := l.typeName(t.Elem())
+		return l.typeName(t.Key()) + "To" + strings.ToUpper(elem[:1]) + elem[1:]
+	}
+
+	return t.Kind().String()
+}
@@ -0,0 +1,117 @@
+package rconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirOptions configuration for reading a directory using VarDefaultsFromDir
+type DirOptions struct {
+	YAMLOptions
+	SubDirectories bool
+}
+
+// DirOption option for reading a directory using VarDefaultsFromDir. Next
+// to WithSubDirectories every YAMLOption (for example WithKeyToLower) can
+// be passed.
+type DirOption interface {
+	applyDir(*DirOptions)
+}
+
+type dirOptionFunc func(*DirOptions)
+
+func (f dirOptionFunc) applyDir(o *DirOptions) { f(o) }
+
+func (o YAMLOption) applyDir(d *DirOptions) { o(&d.YAMLOptions) }
+
+// WithSubDirectories maps sub-directories to dot-separated keys when
+// reading a directory using VarDefaultsFromDir
+func WithSubDirectories() DirOption {
+	return dirOptionFunc(func(o *DirOptions) {
+		o.SubDirectories = true
+	})
+}
+
+// DirProvider creates a VarDefaultsProvider reading the given directory
+// using VarDefaultsFromDir
+func DirProvider(dir string, opts ...DirOption) VarDefaultsProvider {
+	return func() (map[string]string, error) {
+		return VarDefaultsFromDir(dir, opts...)
+	}
+}
+
+// VarDefaultsFromDir creates a vardefaults map from a directory containing one
+// file per key as used by Kubernetes to mount ConfigMaps and Secrets. The file
+// name is used as key, the content with surrounding whitespace trimmed as value.
+// Entries starting with `..` (the `..data` symlink and the timestamped
+// directories of the Kubernetes atomic writer) are skipped. Sub-directories are
+// ignored unless WithSubDirectories is given which maps them to dot-separated
+// keys (`database/host` becomes `database.host`).
+func VarDefaultsFromDir(dir string, opts ...DirOption) (map[string]string, error) {
+	options := &DirOptions{}
+	for _, opt := range opts {
+		opt.applyDir(options)
+	}
+
+	dir, err := ExpandPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	flat := make(map[string]string)
+	if err := readVarDefaultsDir(dir, "", flat, options); err != nil {
+		return nil, err
+	}
+	return flat, nil
+}
+
+func readVarDefaultsDir(dir, prefix string, out map[string]string, opts *DirOptions) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("reading directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+
+		key := entry.Name()
+		if opts.KeyToLower {
+			key = strings.ToLower(key)
+		}
+
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		filename := filepath.Join(dir, entry.Name())
+
+		// Stat follows the symlinks Kubernetes creates for every key
+		info, err := os.Stat(filename)
+		if err != nil {
+			return fmt.Errorf("reading file info: %w", err)
+		}
+
+		switch {
+		case info.IsDir():
+			if !opts.SubDirectories {
+				continue
+			}
+			if err = readVarDefaultsDir(filename, key, out, opts); err != nil {
+				return err
+			}
+
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(filename) //#nosec:G304 // Loading file from var is intended
+			if err != nil {
+				return fmt.Errorf("reading file: %w", err)
+			}
+			out[key] = strings.TrimSpace(string(data))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package rconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createConfigMapMount mimics the layout the Kubernetes atomic writer
+// creates when mounting a ConfigMap or Secret
+func createConfigMapMount(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	dataDir := filepath.Join(dir, "..2024_01_02_15_04_05.123456789")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "database"), 0o700))
+	for name, content := range map[string]string{
+		"username":          "luzifer\n",
+		"Password":          "  secret  \n",
+		"database/host":     "db.example.com",
+		"database/port":     "5432\n",
+		".hidden-by-design": "visible",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dataDir, name), []byte(content), 0o600))
+	}
+
+	require.NoError(t, os.Symlink(filepath.Base(dataDir), filepath.Join(dir, "..data")))
+	for _, name := range []string{"username", "Password", "database", ".hidden-by-design"} {
+		require.NoError(t, os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)))
+	}
+
+	return dir
+}
+
+func TestVarDefaultsFromDir(t *testing.T) {
+	dir := createConfigMapMount(t)
+
+	flat, err := VarDefaultsFromDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"username":          "luzifer",
+		"Password":          "secret",
+		".hidden-by-design": "visible",
+	}, flat)
+
+	flat, err = VarDefaultsFromDir(dir, WithSubDirectories(), WithKeyToLower())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"username":          "luzifer",
+		"password":          "secret",
+		".hidden-by-design": "visible",
+		"database.host":     "db.example.com",
+		"database.port":     "5432",
+	}, flat)
+}
+
+func TestVarDefaultsFromDir_Parse(t *testing.T) {
+	var cfg struct {
+		Username string `vardefault:"username" default:"nobody"`
+		DBHost   string `vardefault:"database.host"`
+		DBPort   int    `vardefault:"database.port"`
+	}
+
+	defaults, err := VarDefaultsFromDir(createConfigMapMount(t), WithSubDirectories())
+	require.NoError(t, err)
+
+	l := New()
+	l.SetVariableDefaults(defaults)
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, "luzifer", cfg.Username)
+	assert.Equal(t, "db.example.com", cfg.DBHost)
+	assert.Equal(t, 5432, cfg.DBPort)
+}
+
+func TestVarDefaultsFromDir_Errors(t *testing.T) {
+	_, err := VarDefaultsFromDir("/tmp/definitely_not_existing_dir_1234567890")
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.Symlink("does-not-exist", filepath.Join(dir, "dangling")))
+	_, err = VarDefaultsFromDir(dir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package rconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VarDefaultsFromINIFile reads contents of a file and calls VarDefaultsFromINI
+func VarDefaultsFromINIFile(filename string, opts ...YAMLOption) (map[string]string, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return VarDefaultsFromINI(data, opts...)
+}
+
+// VarDefaultsFromINI creates a vardefaults map from INI raw data. Keys within
+// a section are prefixed with the section name (`[database]` and `host = db`
+// results in `database.host`), keys before the first section are used as-is.
+// Lines starting with `;` or `#` are treated as comments, values may be
+// quoted using double or single quotes.
+func VarDefaultsFromINI(in []byte, opts ...YAMLOption) (map[string]string, error) {
+	options := &YAMLOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var (
+		flat    = make(map[string]string)
+		scanner = bufio.NewScanner(bytes.NewReader(in))
+		section string
+		lineNo  int
+	)
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "", strings.HasPrefix(line, ";"), strings.HasPrefix(line, "#"):
+			continue
+
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("parsing ini: line %d: unterminated section header", lineNo)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, ok := cutINIKeyValue(line)
+		if !ok {
+			return nil, fmt.Errorf("parsing ini: line %d: expected key = value", lineNo)
+		}
+
+		value, err := unquoteINIValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("parsing ini: line %d: %w", lineNo, err)
+		}
+
+		if section != "" {
+			key = section + "." + key
+		}
+
+		if options.KeyToLower {
+			key = strings.ToLower(key)
+		}
+
+		flat[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading ini: %w", err)
+	}
+
+	return flat, nil
+}
+
+// cutINIKeyValue splits the line at the first `=` or `:` separator
+func cutINIKeyValue(line string) (key, value string, ok bool) {
+	idx := strings.IndexAny(line, "=:")
+	if idx < 1 {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:]), true
+}
+
+// unquoteINIValue removes surrounding quotes from the value. Double quoted
+// values support Go escape sequences, unquoted values may carry an inline
+// comment introduced by ` ;` or ` #`.
+func unquoteINIValue(value string) (string, error) {
+	switch {
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		v, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value: %w", err)
+		}
+		return v, nil
+
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1], nil
+	}
+
+	for _, marker := range []string{" ;", " #"} {
+		if idx := strings.Index(value, marker); idx >= 0 {
+			value = strings.TrimSpace(value[:idx])
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,73 @@
+package rconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarDefaultsFromINI(t *testing.T) {
+	iniData := `
+; global settings
+username = luzifer
+
+[config.rabbitmq]
+host = test-host
+port: 1234
+vhost = "/test vhost"
+password = 'p#ss;word'
+client_id = test-client ; inline comment
+
+# logging settings
+[ config.logging ]
+level=debug
+empty =
+`
+
+	flat, err := VarDefaultsFromINI([]byte(iniData))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"username":                  "luzifer",
+		"config.rabbitmq.host":      "test-host",
+		"config.rabbitmq.port":      "1234",
+		"config.rabbitmq.vhost":     "/test vhost",
+		"config.rabbitmq.password":  "p#ss;word",
+		"config.rabbitmq.client_id": "test-client",
+		"config.logging.level":      "debug",
+		"config.logging.empty":      "",
+	}, flat)
+}
+
+func TestVarDefaultsFromINI_LowerCase(t *testing.T) {
+	flat, err := VarDefaultsFromINI([]byte("[Config.RabbitMQ]\nHost = Test-Host\n"), WithKeyToLower())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"config.rabbitmq.host": "Test-Host"}, flat)
+}
+
+func TestVarDefaultsFromINIFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "defaults.ini")
+	require.NoError(t, os.WriteFile(fn, []byte("[user]\nname = luzifer\n"), 0o600))
+
+	defaults, err := VarDefaultsFromINIFile(fn)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user.name": "luzifer"}, defaults)
+
+	_, err = VarDefaultsFromINIFile("/tmp/definitely_not_existing_file_1234567890.ini")
+	assert.Error(t, err)
+}
+
+func TestVarDefaultsFromINI_Errors(t *testing.T) {
+	for name, data := range map[string]string{
+		"unterminated section": "[section\nkey = value\n",
+		"missing separator":    "[section]\nkey\n",
+		"missing key":          "= value\n",
+		"invalid quoting":      "key = \"\\q\"\n",
+	} {
+		_, err := VarDefaultsFromINI([]byte(data))
+		assert.ErrorContains(t, err, "parsing ini", name)
+	}
+}
@@ -0,0 +1,159 @@
+package rconfig
+
+import (
+	"errors"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// VarDefaultsProvider produces a vardefaults map, for example by reading a
+// configuration file
+type VarDefaultsProvider func() (map[string]string, error)
+
+// FileProvider creates a VarDefaultsProvider reading the given file using one
+// of the VarDefaultsFrom*File functions:
+//
+//	FileProvider(VarDefaultsFromYAMLFile, "/etc/app.yml", WithKeyToLower())
+func FileProvider(read func(string, ...YAMLOption) (map[string]string, error), filename string, opts ...YAMLOption) VarDefaultsProvider {
+	return func() (map[string]string, error) {
+		return read(filename, opts...)
+	}
+}
+
+// Optional wraps a VarDefaultsProvider to yield an empty map instead of an
+// error when the file or directory it reads does not exist
+func Optional(p VarDefaultsProvider) VarDefaultsProvider {
+	return func() (map[string]string, error) {
+		defaults, err := p()
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return defaults, err
+	}
+}
+
+// MergeVarDefaults merges multiple vardefaults maps into a new one. For keys
+// present in multiple sources the value of the later source wins. Lists
+// (stored as "hosts" and / or indexed keys "hosts.0" to "hosts.n") are
+// replaced as a whole: if a later source sets a list, all its elements are
+// taken from that source.
+func MergeVarDefaults(sources ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, src := range sources {
+		replaced := listKeys(src)
+		for list := range listKeys(merged) {
+			if _, ok := src[list]; ok {
+				// Joined value replacing a list of an earlier source
+				replaced[list] = true
+			}
+		}
+
+		for k := range merged {
+			if isListEntry(k, replaced) {
+				delete(merged, k)
+			}
+		}
+
+		for k, v := range src {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// listKeys returns the keys holding a list in the given vardefaults: keys
+// with indexed children numbered from 0 to n without gaps ("servers" for
+// "servers.0.host" and "servers.1.host"). Numeric keys of maps ("ports.80")
+// are not taken for a list.
+func listKeys(defaults map[string]string) map[string]bool {
+	indexes := make(map[string]map[int]bool)
+	for k := range defaults {
+		parts := strings.Split(k, ".")
+		for i := 1; i < len(parts); i++ {
+			idx, ok := listIndex(parts[i])
+			if !ok {
+				continue
+			}
+
+			parent := strings.Join(parts[:i], ".")
+			if indexes[parent] == nil {
+				indexes[parent] = make(map[int]bool)
+			}
+			indexes[parent][idx] = true
+		}
+	}
+
+	lists := make(map[string]bool)
+	for parent, idx := range indexes {
+		complete := true
+		for i := 0; i < len(idx); i++ {
+			complete = complete && idx[i]
+		}
+		if complete {
+			lists[parent] = true
+		}
+	}
+	return lists
+}
+
+// isListEntry checks whether the key is one of the lists or an element
+// (or a value nested into an element) of one of them
+func isListEntry(key string, lists map[string]bool) bool {
+	if lists[key] {
+		return true
+	}
+
+	parts := strings.Split(key, ".")
+	for i := 1; i < len(parts); i++ {
+		if _, ok := listIndex(parts[i]); ok && lists[strings.Join(parts[:i], ".")] {
+			return true
+		}
+	}
+	return false
+}
+
+// listIndex parses the key segment as index of a list element
+func listIndex(s string) (int, bool) {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+
+	idx, err := strconv.Atoi(s)
+	return idx, err == nil
+}
+
+// SetVariableDefaultsFrom loads all providers in order and presets the parser
+// with their merged results, values of later providers win. This allows a
+// layered setup in one call:
+//
+//	SetVariableDefaultsFrom(
+//		Optional(FileProvider(VarDefaultsFromYAMLFile, "/etc/app.yml")),
+//		Optional(FileProvider(VarDefaultsFromYAMLFile, "~/.config/app.yml")),
+//		Optional(FileProvider(VarDefaultsFromYAMLFile, "./app.yml")),
+//	)
+func SetVariableDefaultsFrom(providers ...VarDefaultsProvider) error {
+	return defaultLoader.SetVariableDefaultsFrom(providers...)
+}
+
+// SetVariableDefaultsFrom loads all providers in order and presets this
+// Loader with their merged results. See the package level
+// SetVariableDefaultsFrom for details.
+func (l *Loader) SetVariableDefaultsFrom(providers ...VarDefaultsProvider) error {
+	sources := make([]map[string]string, 0, len(providers))
+	for _, p := range providers {
+		defaults, err := p()
+		if err != nil {
+			return err
+		}
+		sources = append(sources, defaults)
+	}
+
+	l.SetVariableDefaults(MergeVarDefaults(sources...))
+	return nil
+}
@@ -0,0 +1,121 @@
+package rconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeVarDefaults(t *testing.T) {
+	system := map[string]string{"a": "system", "b": "system", "c": "system"}
+	user := map[string]string{"b": "user"}
+	local := map[string]string{"c": "local", "d": "local"}
+
+	assert.Equal(t, map[string]string{
+		"a": "system",
+		"b": "user",
+		"c": "local",
+		"d": "local",
+	}, MergeVarDefaults(system, user, nil, local))
+
+	assert.Equal(t, map[string]string{"a": "system", "b": "system", "c": "system"}, system, "sources must not be modified")
+	assert.Empty(t, MergeVarDefaults())
+}
+
+func TestMergeVarDefaultsLists(t *testing.T) {
+	long, err := VarDefaultsFromYAML([]byte("hosts: [a, b, c]\nservers:\n  - host: a\n  - host: b\nname: long\n"))
+	require.NoError(t, err)
+	short, err := VarDefaultsFromYAML([]byte("hosts: [x]\nservers:\n  - host: x\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"hosts":          "x",
+		"hosts.0":        "x",
+		"servers.0.host": "x",
+		"name":           "long",
+	}, MergeVarDefaults(long, short))
+
+	merged := MergeVarDefaults(long, map[string]string{"hosts": "x,y"})
+	assert.Equal(t, "x,y", merged["hosts"])
+	assert.NotContains(t, merged, "hosts.1", "joined list must replace the elements")
+
+	var cfg struct {
+		Hosts []string `vardefault:"hosts"`
+	}
+
+	l := New()
+	l.SetVariableDefaults(MergeVarDefaults(long, short))
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, []string{"x"}, cfg.Hosts)
+}
+
+func TestMergeVarDefaultsNumericMapKeys(t *testing.T) {
+	first := map[string]string{"ports.80": "http", "db.host": "a"}
+	second := map[string]string{"ports.443": "https"}
+
+	merged := MergeVarDefaults(first, second)
+	assert.Equal(t, map[string]string{
+		"ports.80":  "http",
+		"ports.443": "https",
+		"db.host":   "a",
+	}, merged)
+
+	var cfg struct {
+		Ports map[int]string `vardefault:"ports"`
+	}
+
+	l := New()
+	l.SetVariableDefaults(merged)
+	require.NoError(t, l.parse(&cfg, []string{}))
+	assert.Equal(t, map[int]string{80: "http", 443: "https"}, cfg.Ports)
+
+	// Keys with gaps or leading zeros are no list either
+	assert.Equal(t, map[string]string{"codes.0": "a", "codes.2": "b", "codes.01": "c"},
+		MergeVarDefaults(map[string]string{"codes.0": "a"}, map[string]string{"codes.2": "b", "codes.01": "c"}))
+}
+
+func TestSetVariableDefaultsFrom(t *testing.T) {
+	var cfg struct {
+		Host string `vardefault:"host"`
+		Port int    `vardefault:"port"`
+		User string `vardefault:"user"`
+	}
+
+	dir := t.TempDir()
+	systemFile := filepath.Join(dir, "system.yml")
+	localFile := filepath.Join(dir, "local.json")
+	require.NoError(t, os.WriteFile(systemFile, []byte("host: system\nport: 80\nuser: nobody\n"), 0o600))
+	require.NoError(t, os.WriteFile(localFile, []byte(`{"Port": 8080}`), 0o600))
+
+	l := New()
+	require.NoError(t, l.SetVariableDefaultsFrom(
+		FileProvider(VarDefaultsFromYAMLFile, systemFile),
+		Optional(FileProvider(VarDefaultsFromYAMLFile, filepath.Join(dir, "missing.yml"))),
+		Optional(DirProvider(filepath.Join(dir, "missing.d"), WithSubDirectories())),
+		func() (map[string]string, error) { return map[string]string{"user": "luzifer"}, nil },
+		Optional(FileProvider(VarDefaultsFromJSONFile, localFile, WithKeyToLower())),
+	))
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, "system", cfg.Host)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, "luzifer", cfg.User)
+}
+
+func TestSetVariableDefaultsFrom_Errors(t *testing.T) {
+	dir := t.TempDir()
+	invalidFile := filepath.Join(dir, "invalid.yml")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("- a\n- b\n"), 0o600))
+
+	l := New()
+	l.SetVariableDefaults(map[string]string{"keep": "me"})
+
+	assert.Error(t, l.SetVariableDefaultsFrom(FileProvider(VarDefaultsFromYAMLFile, filepath.Join(dir, "missing.yml"))),
+		"missing files must fail unless optional")
+	assert.Error(t, l.SetVariableDefaultsFrom(Optional(FileProvider(VarDefaultsFromYAMLFile, invalidFile))),
+		"optional files must still be valid")
+	assert.Equal(t, map[string]string{"keep": "me"}, l.variableDefaults, "defaults must be kept on error")
+}
@@ -0,0 +1,147 @@
+package rconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VarDefaultsFromPropertiesFile reads contents of a file and calls VarDefaultsFromProperties
+func VarDefaultsFromPropertiesFile(filename string, opts ...YAMLOption) (map[string]string, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return VarDefaultsFromProperties(data, opts...)
+}
+
+// VarDefaultsFromProperties creates a vardefaults map from Java-style
+// .properties raw data. The dotted keys of the file are used as-is, which
+// matches the keys VarDefaultsFromYAML produces for nested maps. Supported
+// are `=`, `:` and whitespace as separators, `#` and `!` comments, line
+// continuations using a trailing backslash and the escape sequences `\t`,
+// `\n`, `\r`, `\f` and `\uXXXX`.
+func VarDefaultsFromProperties(in []byte, opts ...YAMLOption) (map[string]string, error) {
+	options := &YAMLOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var (
+		flat  = make(map[string]string)
+		lines = strings.Split(strings.ReplaceAll(string(in), "\r\n", "\n"), "\n")
+	)
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// Join continuation lines, dropping the leading whitespace of the following lines
+		for hasContinuation(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if hasContinuation(line) {
+			line = line[:len(line)-1]
+		}
+
+		rawKey, rawValue := cutPropertiesKeyValue(line)
+
+		key, err := unescapeProperties(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("parsing properties: line %d: %w", lineNo, err)
+		}
+
+		value, err := unescapeProperties(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("parsing properties: line %d: %w", lineNo, err)
+		}
+
+		if options.KeyToLower {
+			key = strings.ToLower(key)
+		}
+
+		flat[key] = value
+	}
+
+	return flat, nil
+}
+
+// hasContinuation reports whether the line ends with an odd number of
+// backslashes and therefore continues on the next line
+func hasContinuation(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// cutPropertiesKeyValue splits the logical line at the first unescaped
+// separator (`=`, `:` or whitespace), both parts are still escaped
+func cutPropertiesKeyValue(line string) (key, value string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	// The key may be followed by whitespace and at most one `=` or `:`
+	value = strings.TrimLeft(line[end:], " \t\f")
+	if value != "" && (value[0] == '=' || value[0] == ':') {
+		value = value[1:]
+	}
+
+	return line[:end], strings.TrimLeft(value, " \t\f")
+}
+
+// unescapeProperties resolves the escape sequences of .properties files
+func unescapeProperties(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			out.WriteByte('\t')
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 'f':
+			out.WriteByte('\f')
+		case 'u':
+			if len(s)-i-1 < 4 { //nolint:mnd
+				return "", fmt.Errorf("incomplete unicode escape %q", s[i-1:])
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape %q", s[i-1:i+5])
+			}
+			out.WriteRune(rune(r))
+			i += 4
+		default:
+			out.WriteByte(s[i])
+		}
+	}
+
+	return out.String(), nil
+}
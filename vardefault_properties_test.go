@@ -0,0 +1,78 @@
+package rconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarDefaultsFromProperties(t *testing.T) {
+	propertiesData := `# Generated by JVM tooling
+! alternative comment
+config.rabbitmq.host=test-host
+config.rabbitmq.port : 1234
+config.rabbitmq.vhost /testvhost
+config.logging.dir = /tmp/test\
+    -logs
+config.logging.format=text\\
+config.logging.level=debug
+key\ with\ spaces = value
+key\=with\:separators = value\tTab
+unicode = \u00fcber
+empty =
+   
+`
+
+	flat, err := VarDefaultsFromProperties([]byte(propertiesData))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"config.rabbitmq.host":  "test-host",
+		"config.rabbitmq.port":  "1234",
+		"config.rabbitmq.vhost": "/testvhost",
+		"config.logging.dir":    "/tmp/test-logs",
+		"config.logging.format": `text\`,
+		"config.logging.level":  "debug",
+		"key with spaces":       "value",
+		"key=with:separators":   "value\tTab",
+		"unicode":               "über",
+		"empty":                 "",
+	}, flat)
+
+	yamlFlat, err := VarDefaultsFromYAML([]byte("config:\n  rabbitmq:\n    host: test-host\n    port: 1234\n"))
+	require.NoError(t, err)
+	for k, v := range yamlFlat {
+		assert.Equal(t, v, flat[k], k)
+	}
+}
+
+func TestVarDefaultsFromProperties_LowerCase(t *testing.T) {
+	flat, err := VarDefaultsFromProperties([]byte("Config.RabbitMQ.Host=Test-Host\n"), WithKeyToLower())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"config.rabbitmq.host": "Test-Host"}, flat)
+}
+
+func TestVarDefaultsFromPropertiesFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "defaults.properties")
+	require.NoError(t, os.WriteFile(fn, []byte("username=luzifer\n"), 0o600))
+
+	defaults, err := VarDefaultsFromPropertiesFile(fn)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "luzifer"}, defaults)
+
+	_, err = VarDefaultsFromPropertiesFile("/tmp/definitely_not_existing_file_1234567890.properties")
+	assert.Error(t, err)
+}
+
+func TestVarDefaultsFromProperties_Errors(t *testing.T) {
+	for name, data := range map[string]string{
+		"incomplete unicode": "key = \\u00f\n",
+		"invalid unicode":    "key = \\uzzzz\n",
+	} {
+		_, err := VarDefaultsFromProperties([]byte(data))
+		assert.ErrorContains(t, err, "parsing properties", name)
+	}
+}
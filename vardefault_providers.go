@@ -1,8 +1,12 @@
 package rconfig
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"strconv"
 	"strings"
 
 	"github.com/goccy/go-yaml"
@@ -25,7 +29,7 @@ func WithKeyToLower() YAMLOption {
 
 // VarDefaultsFromYAMLFile reads contents of a file and calls VarDefaultsFromYAML
 func VarDefaultsFromYAMLFile(filename string, opts ...YAMLOption) (map[string]string, error) {
-	data, err := os.ReadFile(filename) //#nosec:G304 // Loading file from var is intended
+	data, err := readFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("reading file: %w", err)
 	}
@@ -49,6 +53,79 @@ func VarDefaultsFromYAML(in []byte, opts ...YAMLOption) (map[string]string, erro
 	return flat, nil
 }
 
+// VarDefaultsFromJSONFile reads contents of a file and calls VarDefaultsFromJSON
+func VarDefaultsFromJSONFile(filename string, opts ...YAMLOption) (map[string]string, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return VarDefaultsFromJSON(data, opts...)
+}
+
+// VarDefaultsFromJSON creates a vardefaults map from JSON raw data, supporting nested
+// objects by flattening keys the same way VarDefaultsFromYAML does.
+func VarDefaultsFromJSON(in []byte, opts ...YAMLOption) (map[string]string, error) {
+	options := &YAMLOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	flat := make(map[string]string)
+	if len(bytes.TrimSpace(in)) == 0 {
+		return flat, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(in))
+	dec.UseNumber()
+
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("parsing json: %w", err)
+	}
+
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing json: unexpected content after top-level object")
+	}
+
+	flattenYAMLMap("", normalizeJSONNumbers(raw).(map[string]interface{}), flat, options)
+	return flat, nil
+}
+
+// normalizeJSONNumbers converts json.Number values into the integer and
+// float types the YAML parser produces so JSON and YAML inputs render
+// identically
+func normalizeJSONNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, e := range val {
+			val[k] = normalizeJSONNumbers(e)
+		}
+		return val
+
+	case []interface{}:
+		for i, e := range val {
+			val[i] = normalizeJSONNumbers(e)
+		}
+		return val
+
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if !strings.ContainsAny(val.String(), ".eE") {
+			// Integers exceeding int64 keep all their digits like in YAML
+			return val.String()
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+
+	default:
+		return val
+	}
+}
+
 // flattenYAMLMap recursively flattens a nested map into dot-separated keys.
 func flattenYAMLMap(prefix string, in map[string]interface{}, out map[string]string, opts *YAMLOptions) {
 	for k, v := range in {
@@ -61,18 +138,54 @@ func flattenYAMLMap(prefix string, in map[string]interface{}, out map[string]str
 			key = prefix + "." + key
 		}
 
-		switch val := v.(type) {
-		case map[string]interface{}:
-			flattenYAMLMap(key, val, out, opts)
-		case map[interface{}]interface{}:
-			// Handle maps with interface{} keys (older YAML libs)
-			m2 := make(map[string]interface{})
-			for mk, mv := range val {
-				m2[fmt.Sprintf("%v", mk)] = mv
+		flattenYAMLValue(key, v, out, opts)
+	}
+}
+
+// flattenYAMLValue stores a single value below the given key. Lists are
+// stored using indexed keys (servers.0.host) and, if they only contain
+// scalars, additionally as comma-separated list. Null values are left out
+// so the field falls back to its default.
+func flattenYAMLValue(key string, v interface{}, out map[string]string, opts *YAMLOptions) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		flattenYAMLMap(key, val, out, opts)
+
+	case map[interface{}]interface{}:
+		// Handle maps with interface{} keys (older YAML libs)
+		m2 := make(map[string]interface{})
+		for mk, mv := range val {
+			m2[fmt.Sprintf("%v", mk)] = mv
+		}
+		flattenYAMLMap(key, m2, out, opts)
+
+	case []map[string]interface{}:
+		// Arrays of tables as produced by the TOML parser
+		for i, e := range val {
+			flattenYAMLMap(key+"."+strconv.Itoa(i), e, out, opts)
+		}
+
+	case []interface{}:
+		scalars := true
+		for i, e := range val {
+			switch e.(type) {
+			case nil:
+				// Keep the indexes continuous
+				out[key+"."+strconv.Itoa(i)] = ""
+				continue
+			case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+				scalars = false
 			}
-			flattenYAMLMap(key, m2, out, opts)
-		default:
-			out[key] = fmt.Sprintf("%v", val)
+			flattenYAMLValue(key+"."+strconv.Itoa(i), e, out, opts)
+		}
+		if scalars {
+			out[key] = formatFileValue(val, ",")
 		}
+
+	case nil:
+		// Left out, see above
+
+	default:
+		out[key] = formatFileValue(val, ",")
 	}
 }
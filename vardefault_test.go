@@ -2,6 +2,7 @@ package rconfig
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -178,3 +179,170 @@ func TestVarDefaultsFromYAML_SliceRoot(t *testing.T) {
 	// Check that it fails with parsing error
 	assert.Contains(t, err.Error(), "parsing yaml")
 }
+
+func TestVarDefaultsFromJSON(t *testing.T) {
+	jsonData := `{
+  "Config": {
+    "RabbitMQ": {
+      "host": "test-host",
+      "port": 1234,
+      "ratio": 0.25,
+      "enabled": false
+    }
+  },
+  "big": 9007199254740993,
+  "huge": 123456789012345678901234567890,
+  "empty": ""
+}`
+	yamlData := `
+Config:
+  RabbitMQ:
+    host: test-host
+    port: 1234
+    ratio: 0.25
+    enabled: false
+big: 9007199254740993
+huge: 123456789012345678901234567890
+empty: ""
+`
+
+	fromJSON, err := VarDefaultsFromJSON([]byte(jsonData))
+	require.NoError(t, err)
+	fromYAML, err := VarDefaultsFromYAML([]byte(yamlData))
+	require.NoError(t, err)
+
+	assert.Equal(t, fromYAML, fromJSON)
+	assert.Equal(t, "1234", fromJSON["Config.RabbitMQ.port"])
+	assert.Equal(t, "9007199254740993", fromJSON["big"])
+	assert.Equal(t, "123456789012345678901234567890", fromJSON["huge"])
+
+	fromJSON, err = VarDefaultsFromJSON([]byte(jsonData), WithKeyToLower())
+	require.NoError(t, err)
+	fromYAML, err = VarDefaultsFromYAML([]byte(yamlData), WithKeyToLower())
+	require.NoError(t, err)
+
+	assert.Equal(t, fromYAML, fromJSON)
+	assert.Equal(t, "test-host", fromJSON["config.rabbitmq.host"])
+}
+
+func TestVarDefaultsFromJSONFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "defaults.json")
+	require.NoError(t, os.WriteFile(fn, []byte(`{"username": "luzifer"}`), 0o600))
+
+	defaults, err := VarDefaultsFromJSONFile(fn)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "luzifer"}, defaults)
+
+	_, err = VarDefaultsFromJSONFile("/tmp/definitely_not_existing_file_1234567890.json")
+	assert.Error(t, err)
+}
+
+func TestVarDefaultsFromJSON_Errors(t *testing.T) {
+	flat, err := VarDefaultsFromJSON([]byte(" \n"))
+	assert.NoError(t, err)
+	assert.Empty(t, flat)
+
+	_, err = VarDefaultsFromJSON([]byte(`["item1", "item2"]`))
+	assert.ErrorContains(t, err, "parsing json")
+
+	_, err = VarDefaultsFromJSON([]byte(`{"a": `))
+	assert.ErrorContains(t, err, "parsing json")
+
+	_, err = VarDefaultsFromJSON([]byte(`{"a": 1} {"b": 2} garbage`))
+	assert.ErrorContains(t, err, "parsing json")
+
+	_, err = VarDefaultsFromJSON([]byte(`{"a": 1} garbage`))
+	assert.ErrorContains(t, err, "parsing json")
+
+	flat, err = VarDefaultsFromJSON([]byte("{\"a\": 1}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1"}, flat)
+}
+
+func TestVarDefaultsFromYAML_Lists(t *testing.T) {
+	defaults, err := VarDefaultsFromYAML([]byte(`---
+tags: [a, b, c]
+ports: [80, 443]
+empty: []
+nullable: ~
+big: 1000000.0
+small: 0.5
+servers:
+  - host: one
+    port: 1
+  - host: two
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"tags":           "a,b,c",
+		"tags.0":         "a",
+		"tags.1":         "b",
+		"tags.2":         "c",
+		"ports":          "80,443",
+		"ports.0":        "80",
+		"ports.1":        "443",
+		"empty":          "",
+		"big":            "1000000",
+		"small":          "0.5",
+		"servers.0.host": "one",
+		"servers.0.port": "1",
+		"servers.1.host": "two",
+	}, defaults)
+}
+
+func TestVardefaultParsing_Lists(t *testing.T) {
+	var cfg struct {
+		Tags     []string `vardefault:"tags"`
+		Paths    []string `vardefault:"paths" delimiter:";"`
+		Ports    []int    `vardefault:"ports"`
+		Host     string   `vardefault:"servers.1.host"`
+		Nullable int      `vardefault:"nullable" default:"42"`
+	}
+
+	defaults, err := VarDefaultsFromYAML([]byte(`---
+tags: [a, b]
+paths: ["/a,b", "/c"]
+ports: [80, 443]
+nullable: null
+servers:
+  - host: one
+  - host: two
+`))
+	require.NoError(t, err)
+
+	l := New()
+	l.SetVariableDefaults(defaults)
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, []string{"/a,b", "/c"}, cfg.Paths)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Equal(t, "two", cfg.Host)
+	assert.Equal(t, 42, cfg.Nullable)
+}
+
+func TestVardefaultParsing_IndexedElements(t *testing.T) {
+	var cfg struct {
+		List    []string `vardefault:"l"`
+		Ports   []int    `vardefault:"ports"`
+		Invalid []int    `vardefault:"invalid"`
+	}
+
+	l := New()
+	l.SetVariableDefaults(map[string]string{
+		"l":       "a,b,c",
+		"l.0":     "a,b",
+		"l.1":     "c",
+		"ports.0": " 80",
+		"ports.1": "443",
+	})
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.Equal(t, []string{"a,b", "c"}, cfg.List)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Empty(t, cfg.Invalid)
+
+	l.SetVariableDefaults(map[string]string{"invalid.0": "1", "invalid.1": "x"})
+	assert.Error(t, l.parse(&cfg, []string{}))
+}
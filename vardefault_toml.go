@@ -0,0 +1,34 @@
+package rconfig
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// VarDefaultsFromTOMLFile reads contents of a file and calls VarDefaultsFromTOML
+func VarDefaultsFromTOMLFile(filename string, opts ...YAMLOption) (map[string]string, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return VarDefaultsFromTOML(data, opts...)
+}
+
+// VarDefaultsFromTOML creates a vardefaults map from TOML raw data, flattening
+// tables into dot-separated keys the same way VarDefaultsFromYAML does.
+func VarDefaultsFromTOML(in []byte, opts ...YAMLOption) (map[string]string, error) {
+	options := &YAMLOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	raw := make(map[string]interface{})
+	if err := toml.Unmarshal(in, &raw); err != nil {
+		return nil, fmt.Errorf("parsing toml: %w", err)
+	}
+
+	flat := make(map[string]string)
+	flattenYAMLMap("", raw, flat, options)
+	return flat, nil
+}
@@ -0,0 +1,117 @@
+package rconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarDefaultsFromTOML(t *testing.T) {
+	tomlData := `
+username = "luzifer"
+
+[config.rabbitmq]
+host = "test-host"
+port = 1234
+vhost = "/testvhost"
+
+[config.logging]
+level = "debug"
+add_source = false
+`
+	yamlData := `
+username: luzifer
+config:
+  rabbitmq:
+    host: test-host
+    port: 1234
+    vhost: /testvhost
+  logging:
+    level: debug
+    add_source: false
+`
+
+	fromTOML, err := VarDefaultsFromTOML([]byte(tomlData))
+	require.NoError(t, err)
+	fromYAML, err := VarDefaultsFromYAML([]byte(yamlData))
+	require.NoError(t, err)
+
+	assert.Equal(t, fromYAML, fromTOML)
+	assert.Equal(t, "1234", fromTOML["config.rabbitmq.port"])
+	assert.Equal(t, "false", fromTOML["config.logging.add_source"])
+}
+
+func TestVarDefaultsFromTOML_LowerCase(t *testing.T) {
+	flat, err := VarDefaultsFromTOML([]byte("[Config.RabbitMQ]\nHost = \"Test-Host\"\n"), WithKeyToLower())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"config.rabbitmq.host": "Test-Host"}, flat)
+}
+
+func TestVarDefaultsFromTOMLFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "defaults.toml")
+	require.NoError(t, os.WriteFile(fn, []byte("username = \"luzifer\"\n"), 0o600))
+
+	defaults, err := VarDefaultsFromTOMLFile(fn)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "luzifer"}, defaults)
+
+	_, err = VarDefaultsFromTOMLFile("/tmp/definitely_not_existing_file_1234567890.toml")
+	assert.Error(t, err)
+}
+
+func TestVarDefaultsFromTOML_Errors(t *testing.T) {
+	flat, err := VarDefaultsFromTOML([]byte(""))
+	assert.NoError(t, err)
+	assert.Empty(t, flat)
+
+	_, err = VarDefaultsFromTOML([]byte("username = luzifer\n"))
+	assert.ErrorContains(t, err, "parsing toml")
+}
+
+func TestVarDefaultsFromTOML_ArrayOfTables(t *testing.T) {
+	defaults, err := VarDefaultsFromTOML([]byte("[[servers]]\nhost = \"one\"\n\n[[servers]]\nhost = \"two\"\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"servers.0.host": "one",
+		"servers.1.host": "two",
+	}, defaults)
+}
+
+func TestVarDefaultsFromTOML_Times(t *testing.T) {
+	defaults, err := VarDefaultsFromTOML([]byte(`
+[s]
+at = 1979-05-27T07:32:00.5-08:00
+local = 1979-05-27T07:32:00
+day = 1979-05-27
+clock = 07:32:00.25
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"s.at":    "1979-05-27T07:32:00.5-08:00",
+		"s.local": "1979-05-27 07:32:00",
+		"s.day":   "1979-05-27",
+		"s.clock": "07:32:00.25",
+	}, defaults)
+
+	var cfg struct {
+		At    time.Time `vardefault:"s.at"`
+		Local time.Time `vardefault:"s.local"`
+		Day   time.Time `vardefault:"s.day"`
+		Clock time.Time `vardefault:"s.clock"`
+	}
+
+	l := New()
+	l.SetVariableDefaults(defaults)
+	require.NoError(t, l.parse(&cfg, []string{}))
+
+	assert.True(t, time.Date(1979, 5, 27, 7, 32, 0, 5e8, time.FixedZone("", -8*3600)).Equal(cfg.At))
+	assert.Equal(t, time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC), cfg.Local)
+	assert.Equal(t, time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC), cfg.Day)
+	assert.Equal(t, time.Date(0, 1, 1, 7, 32, 0, 25e7, time.UTC), cfg.Clock)
+}